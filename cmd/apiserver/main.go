@@ -0,0 +1,184 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+// Command apiserver is a reference implementation of the metadata API gateway described in src/core/dl/README.md.
+//
+// Usage:
+//
+//	go run ./cmd/apiserver -addr :8080 [-key secret] [-dir ./media]
+//
+// With -dir set, the server works offline: a request for video ID "abc" is answered with the first file
+// in the directory named "abc.*", served back under /files/. Without -dir, tracks are resolved with yt-dlp.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"ashokshau/tgmusic/src/core/dl"
+)
+
+var videoIDRegex = regexp.MustCompile(`(?:v=|youtu\.be/|shorts/)([\w-]{11})`)
+
+// server holds the flags the gateway was started with.
+type server struct {
+	key   string
+	dir   string
+	ytdlp string
+}
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	key := flag.String("key", "", "API key expected in the X-API-Key header (empty disables the check)")
+	dir := flag.String("dir", "", "serve tracks from this directory instead of resolving them with yt-dlp")
+	ytdlp := flag.String("ytdlp", "yt-dlp", "path to the yt-dlp binary")
+	flag.Parse()
+
+	s := &server{key: *key, dir: *dir, ytdlp: *ytdlp}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handleResolve)
+	if s.dir != "" {
+		mux.Handle("/files/", http.StripPrefix("/files/", http.FileServer(http.Dir(s.dir))))
+	}
+
+	log.Printf("API gateway listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, mux))
+}
+
+// writeJSON writes resp with the given status code.
+func writeJSON(w http.ResponseWriter, status int, resp dl.ApiResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// handleResolve implements `GET /?url=<track URL>`.
+func (s *server) handleResolve(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, dl.ApiResponse{Message: "method not allowed"})
+		return
+	}
+
+	if s.key != "" && r.Header.Get(dl.ApiKeyHeader) != s.key {
+		writeJSON(w, http.StatusUnauthorized, dl.ApiResponse{Message: "invalid or missing API key"})
+		return
+	}
+
+	query := r.URL.Query().Get("url")
+	match := videoIDRegex.FindStringSubmatch(query)
+	if match == nil {
+		writeJSON(w, http.StatusBadRequest, dl.ApiResponse{Message: "the url parameter is not a supported track URL"})
+		return
+	}
+
+	var (
+		resp dl.ApiResponse
+		err  error
+	)
+	if s.dir != "" {
+		resp, err = s.resolveLocal(r, match[1])
+	} else {
+		resp, err = s.resolveYtDlp(r.Context(), match[1])
+	}
+
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, dl.ApiResponse{Message: err.Error()})
+		return
+	}
+
+	resp.Status = true
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// resolveLocal answers with a file named after the video ID from the -dir directory.
+func (s *server) resolveLocal(r *http.Request, videoID string) (dl.ApiResponse, error) {
+	matches, _ := filepath.Glob(filepath.Join(s.dir, videoID+".*"))
+	if len(matches) == 0 {
+		return dl.ApiResponse{}, fmt.Errorf("no local file for %s", videoID)
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	name := filepath.Base(matches[0])
+	link := fmt.Sprintf("%s://%s/files/%s", scheme, r.Host, name)
+
+	resp := dl.ApiResponse{
+		Title: strings.TrimSuffix(name, filepath.Ext(name)),
+		Audio: link,
+	}
+	if ext := strings.ToLower(filepath.Ext(name)); ext == ".mp4" || ext == ".mkv" || ext == ".webm" {
+		resp.Videos = map[string]string{"720": link}
+	}
+	return resp, nil
+}
+
+// ytDlpInfo is the subset of `yt-dlp --dump-single-json` output used by the gateway.
+type ytDlpInfo struct {
+	Title     string  `json:"title"`
+	Thumbnail string  `json:"thumbnail"`
+	Duration  float64 `json:"duration"`
+	Formats   []struct {
+		URL    string  `json:"url"`
+		Height int     `json:"height"`
+		VCodec string  `json:"vcodec"`
+		ACodec string  `json:"acodec"`
+		ABR    float64 `json:"abr"`
+	} `json:"formats"`
+}
+
+// resolveYtDlp resolves direct media URLs for a video ID with yt-dlp.
+func (s *server) resolveYtDlp(ctx context.Context, videoID string) (dl.ApiResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, s.ytdlp, "--no-warnings", "--dump-single-json", "https://www.youtube.com/watch?v="+videoID)
+	output, err := cmd.Output()
+	if err != nil {
+		return dl.ApiResponse{}, fmt.Errorf("yt-dlp failed: %w", err)
+	}
+
+	var info ytDlpInfo
+	if err := json.Unmarshal(output, &info); err != nil {
+		return dl.ApiResponse{}, fmt.Errorf("failed to parse yt-dlp output: %w", err)
+	}
+
+	resp := dl.ApiResponse{
+		Title:     info.Title,
+		Thumbnail: info.Thumbnail,
+		Duration:  int(info.Duration),
+		Videos:    map[string]string{},
+	}
+
+	var bestABR float64
+	for _, f := range info.Formats {
+		switch {
+		case f.VCodec == "none" && f.ACodec != "none" && f.ABR >= bestABR:
+			bestABR = f.ABR
+			resp.Audio = f.URL
+		case f.VCodec != "none" && f.ACodec != "none" && f.Height > 0:
+			resp.Videos[strconv.Itoa(f.Height)] = f.URL
+		}
+	}
+
+	if resp.Audio == "" {
+		return dl.ApiResponse{}, fmt.Errorf("no audio format found for %s", videoID)
+	}
+	return resp, nil
+}
@@ -34,12 +34,22 @@ func LoadConfig() error {
                 SessionType:       getEnvStr("SESSION_TYPE", "pyrogram"),
                 MongoUri:          os.Getenv("MONGO_URI"),
                 DbName:            getEnvStr("DB_NAME", "MusicBot"),
-                ApiUrl:            getEnvStr("API_URL", "https://tgmusic.fallenapi.fun"),
+                ApiUrl:            os.Getenv("API_URL"),
                 ApiKey:            os.Getenv("API_KEY"),
                 OwnerId:           getEnvInt64("OWNER_ID"),
                 LoggerId:          getEnvInt64("LOGGER_ID"),
                 Proxy:             os.Getenv("PROXY"),
                 DefaultService:    strings.ToLower(getEnvStr("DEFAULT_SERVICE", "youtube")),
+                DefaultQuality:    strings.ToLower(getEnvStr("DEFAULT_QUALITY", QualityStandard)),
+                Progressive:       getEnvBool("PROGRESSIVE_PLAYBACK", false),
+                Engine:            strings.ToLower(getEnvStr("PLAYBACK_ENGINE", EngineShell)),
+                AssistantStrategy: strings.ToLower(getEnvStr("ASSISTANT_STRATEGY", StrategyLeastChats)),
+                RecordMaxDuration: getEnvInt64("RECORD_MAX_DURATION"),
+                RecordPartSize:    getEnvInt64("RECORD_PART_SIZE"),
+                RecordDestination: strings.ToLower(getEnvStr("RECORD_DESTINATION", RecordToChat)),
+                PrivateCalls:      strings.ToLower(getEnvStr("PRIVATE_CALLS", PrivateCallsAnswer)),
+                CallGreeting:      getEnvStr("CALL_GREETING", "https://t.me/FallenSongs/1295"),
+                TtsCommand:        os.Getenv("TTS_COMMAND"),
                 MaxFileSize:       getEnvInt64("MAX_FILE_SIZE"),
                 SongDurationLimit: getEnvInt64("SONG_DURATION_LIMIT"),
                 DownloadsDir:      getEnvStr("DOWNLOADS_DIR", "/tmp/downloads"),
@@ -84,7 +94,7 @@ func LoadConfig() error {
         }
 
         if len(Conf.cookiesUrl) > 0 {
-                if err := os.MkdirAll(cookiesDr, 0750); err != nil {
+                if err := os.MkdirAll(CookiesDir, 0750); err != nil {
                         return fmt.Errorf("failed to create temp dir: %w", err)
                 }
                 go saveAllCookies(Conf.cookiesUrl)
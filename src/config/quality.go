@@ -0,0 +1,96 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package config
+
+import "strings"
+
+// QualityProfile groups the download and streaming settings that together decide a stream's quality and bandwidth.
+type QualityProfile struct {
+	Name         string // Name is the profile identifier used in settings and commands.
+	AudioFormat  string // AudioFormat is the yt-dlp format selector for audio downloads. The API gateway serves a single audio format.
+	VideoFormat  string // VideoFormat is the yt-dlp format selector for video downloads.
+	SampleRate   uint32 // SampleRate is the PCM sample rate sent to the call.
+	ChannelCount uint8  // ChannelCount is the number of audio channels sent to the call.
+	Width        int    // Width is the maximum video width sent to the call.
+	Height       int    // Height is the maximum video height sent to the call.
+	Fps          uint8  // Fps is the video frame rate sent to the call.
+}
+
+const (
+	QualityLow      = "low"
+	QualityStandard = "standard"
+	QualityHigh     = "high"
+	QualityBest     = "best"
+)
+
+// QualityProfiles lists the available profiles from the lightest to the heaviest.
+var QualityProfiles = []QualityProfile{
+	{
+		Name:         QualityLow,
+		AudioFormat:  "bestaudio[abr<=70]/worstaudio/bestaudio/best",
+		VideoFormat:  "best[ext=mp4][height<=360]/worst[ext=mp4]/worst",
+		SampleRate:   48000,
+		ChannelCount: 1,
+		Width:        640,
+		Height:       360,
+		Fps:          24,
+	},
+	{
+		Name:         QualityStandard,
+		AudioFormat:  "bestaudio[ext=m4a]/bestaudio[ext=mp4]/bestaudio[ext=webm]/bestaudio/best",
+		VideoFormat:  "bestvideo[ext=mp4][height<=720]+bestaudio[ext=m4a]/best[ext=mp4][height<=720]",
+		SampleRate:   96000,
+		ChannelCount: 2,
+		Width:        1280,
+		Height:       720,
+		Fps:          30,
+	},
+	{
+		Name:         QualityHigh,
+		AudioFormat:  "bestaudio[ext=m4a]/bestaudio[ext=mp4]/bestaudio[ext=webm]/bestaudio/best",
+		VideoFormat:  "bestvideo[ext=mp4][height<=1080]+bestaudio[ext=m4a]/best[ext=mp4][height<=1080]",
+		SampleRate:   96000,
+		ChannelCount: 2,
+		Width:        1920,
+		Height:       1080,
+		Fps:          30,
+	},
+	{
+		Name:         QualityBest,
+		AudioFormat:  "bestaudio[acodec=opus]/bestaudio/best",
+		VideoFormat:  "bestvideo[height<=1080]+bestaudio/best[height<=1080]",
+		SampleRate:   96000,
+		ChannelCount: 2,
+		Width:        1920,
+		Height:       1080,
+		Fps:          60,
+	},
+}
+
+// GetQualityProfile looks up a profile by name.
+// It returns the profile and true if found, otherwise the standard profile and false.
+func GetQualityProfile(name string) (QualityProfile, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	// The best profile was called lossless, which saved settings may still use.
+	if name == "lossless" {
+		name = QualityBest
+	}
+	for _, p := range QualityProfiles {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return QualityProfiles[1], false
+}
+
+// isValidQuality checks if the quality profile name is known.
+func isValidQuality(name string) bool {
+	_, ok := GetQualityProfile(name)
+	return ok
+}
@@ -15,9 +15,41 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 )
 
-const cookiesDr = "src/cookies"
+// CookiesDir is the directory where cookies files for yt-dlp are stored.
+const CookiesDir = "src/cookies"
+
+// cookiesMu guards Conf.CookiesPath, which the boot-time download and the cookie pool change at runtime.
+var cookiesMu sync.Mutex
+
+// CookiePaths returns a copy of the paths of the cookies files.
+func CookiePaths() []string {
+	cookiesMu.Lock()
+	defer cookiesMu.Unlock()
+	return append([]string(nil), Conf.CookiesPath...)
+}
+
+// AddCookiePath adds a cookies file to Conf.CookiesPath.
+func AddCookiePath(path string) {
+	cookiesMu.Lock()
+	defer cookiesMu.Unlock()
+	Conf.CookiesPath = append(Conf.CookiesPath, path)
+}
+
+// RemoveCookiePath removes a cookies file from Conf.CookiesPath.
+func RemoveCookiePath(path string) {
+	cookiesMu.Lock()
+	defer cookiesMu.Unlock()
+	var kept []string
+	for _, c := range Conf.CookiesPath {
+		if c != path {
+			kept = append(kept, c)
+		}
+	}
+	Conf.CookiesPath = kept
+}
 
 // fetchContent downloads content from Pastebin or Batbin.
 // It takes a URL as input.
@@ -66,7 +98,7 @@ func saveContent(url, content string) (string, error) {
 	}
 	filename += ".txt"
 
-	filePath := filepath.Join(cookiesDr, filename)
+	filePath := filepath.Join(CookiesDir, filename)
 	// #nosec G304
 	f, err := os.Create(filePath)
 	if err != nil {
@@ -99,6 +131,6 @@ func saveAllCookies(urls []string) {
 			continue
 		}
 
-		Conf.CookiesPath = append(Conf.CookiesPath, path)
+		AddCookiePath(path)
 	}
 }
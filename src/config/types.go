@@ -16,6 +16,41 @@ import (
 	"strings"
 )
 
+const (
+	// EngineShell lets ntgcalls run one ffmpeg shell command per stream.
+	EngineShell = "shell"
+	// EngineExternal decodes audio in a Go-managed pipeline per chat and sends the frames to ntgcalls.
+	// Video streams still use the shell engine, and seeks restart the decoder.
+	EngineExternal = "external"
+)
+
+const (
+	// StrategyLeastCalls gives a new chat to the assistant with the fewest active calls.
+	StrategyLeastCalls = "least_calls"
+	// StrategyLeastChats gives a new chat to the assistant with the fewest assigned chats.
+	StrategyLeastChats = "least_chats"
+	// StrategyHash gives a new chat to the assistant its ID hashes to, so adding an assistant moves few chats.
+	StrategyHash = "hash"
+	// StrategyRandom gives a new chat to a random assistant.
+	StrategyRandom = "random"
+)
+
+const (
+	// RecordToChat uploads voice chat recordings to the recorded chat.
+	RecordToChat = "chat"
+	// RecordToLogger uploads voice chat recordings to the logger group.
+	RecordToLogger = "logger"
+)
+
+const (
+	// PrivateCallsOff declines private calls to the assistants.
+	PrivateCallsOff = "off"
+	// PrivateCallsQueue rings until the caller queues a track from the bot's DM, then answers with it.
+	PrivateCallsQueue = "queue"
+	// PrivateCallsAnswer answers private calls at once with the greeting.
+	PrivateCallsAnswer = "answer"
+)
+
 // BotConfig holds the configuration for the bot.
 type BotConfig struct {
 	ApiId             int32    // ApiId is the Telegram API ID.
@@ -25,12 +60,22 @@ type BotConfig struct {
 	SessionType       string   // SessionType is the type of session (pyrogram/telethon/gogram).
 	MongoUri          string   // MongoUri is the MongoDB connection string.
 	DbName            string   // DbName is the name of the database.
-	ApiUrl            string   // ApiUrl is the URL of the API.
-	ApiKey            string   // ApiKey is the API key.
+	ApiUrl            string   // ApiUrl is the base URL of the metadata API gateway (see src/core/dl/README.md).
+	ApiKey            string   // ApiKey is sent to the API gateway in the X-API-Key header.
 	OwnerId           int64    // OwnerId is the user ID of the bot owner.
 	LoggerId          int64    // LoggerId is the group ID of the bot logger.
 	Proxy             string   // Proxy is the proxy URL for the bot.
 	DefaultService    string   // DefaultService is the default search platform.
+	DefaultQuality    string   // DefaultQuality is the quality profile used by chats without their own setting.
+	Progressive       bool     // Progressive starts audio from the CDN URL while the file downloads in the background.
+	Engine            string   // Engine is the playback engine for audio: "shell" or "external".
+	AssistantStrategy string   // AssistantStrategy picks the assistant of a new chat: "least_calls", "least_chats", "hash" or "random".
+	RecordMaxDuration int64    // RecordMaxDuration is the longest a voice chat recording runs, in minutes.
+	RecordPartSize    int64    // RecordPartSize is the size, in MB, after which a recording continues in a new part.
+	RecordDestination string   // RecordDestination is where recordings are uploaded: "chat" or "logger".
+	PrivateCalls      string   // PrivateCalls is how the assistants handle private calls: "off", "queue" or "answer".
+	CallGreeting      string   // CallGreeting is the clip a private call is answered with, empty for silence.
+	TtsCommand        string   // TtsCommand renders track announcements, with {text}, {output} and {lang} placeholders.
 	MaxFileSize       int64    // MaxFileSize is the maximum file size for downloads.
 	SongDurationLimit int64    // SongDurationLimit is the maximum duration of a song in seconds.
 	DownloadsDir      string   // DownloadsDir is the directory where downloads are stored.
@@ -93,6 +138,18 @@ func getEnvInt64(key string) int64 {
 	return 0
 }
 
+// getEnvBool gets environment variable as bool with default value
+func getEnvBool(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	if val, err := strconv.ParseBool(value); err == nil {
+		return val
+	}
+	return defaultValue
+}
+
 // containsInt checks if a slice contains a specific int64 value
 func containsInt(slice []int64, val int64) bool {
 	for _, item := range slice {
@@ -155,6 +212,51 @@ func (c *BotConfig) validate() error {
 		c.SongDurationLimit = 3600 // 1 hour default
 	}
 
+	if !isValidQuality(c.DefaultQuality) {
+		log.Printf("Invalid DEFAULT_QUALITY '%s', defaulting to '%s'", c.DefaultQuality, QualityStandard)
+		c.DefaultQuality = QualityStandard
+	}
+
+	if c.Engine != EngineShell && c.Engine != EngineExternal {
+		log.Printf("Invalid PLAYBACK_ENGINE '%s', defaulting to '%s'", c.Engine, EngineShell)
+		c.Engine = EngineShell
+	}
+
+	switch c.AssistantStrategy {
+	case StrategyLeastCalls, StrategyLeastChats, StrategyHash, StrategyRandom:
+	default:
+		log.Printf("Invalid ASSISTANT_STRATEGY '%s', defaulting to '%s'", c.AssistantStrategy, StrategyLeastChats)
+		c.AssistantStrategy = StrategyLeastChats
+	}
+
+	if c.RecordMaxDuration <= 0 {
+		c.RecordMaxDuration = 180 // 3 hours default
+	}
+
+	if c.RecordPartSize <= 0 {
+		c.RecordPartSize = 200 // 200MB default
+	}
+
+	if c.RecordDestination != RecordToChat && c.RecordDestination != RecordToLogger {
+		log.Printf("Invalid RECORD_DESTINATION '%s', defaulting to '%s'", c.RecordDestination, RecordToChat)
+		c.RecordDestination = RecordToChat
+	}
+	if c.RecordDestination == RecordToLogger && c.LoggerId == 0 {
+		log.Printf("RECORD_DESTINATION is '%s' but LOGGER_ID is not set, defaulting to '%s'", RecordToLogger, RecordToChat)
+		c.RecordDestination = RecordToChat
+	}
+
+	switch c.PrivateCalls {
+	case PrivateCallsOff, PrivateCallsQueue, PrivateCallsAnswer:
+	default:
+		log.Printf("Invalid PRIVATE_CALLS '%s', defaulting to '%s'", c.PrivateCalls, PrivateCallsAnswer)
+		c.PrivateCalls = PrivateCallsAnswer
+	}
+
+	if strings.EqualFold(c.CallGreeting, "off") {
+		c.CallGreeting = ""
+	}
+
 	if !isValidService(c.DefaultService) {
 		c.DefaultService = "youtube"
 		log.Printf("Invalid DEFAULT_SERVICE '%s', defaulting to 'youtube'", c.DefaultService)
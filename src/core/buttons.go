@@ -57,7 +57,7 @@ func SupportKeyboard() *telegram.ReplyInlineMarkup {
 }
 
 // SettingsKeyboard creates an inline keyboard for bot settings
-func SettingsKeyboard(playMode, adminMode string) *telegram.ReplyInlineMarkup {
+func SettingsKeyboard(playMode, adminMode, quality string) *telegram.ReplyInlineMarkup {
         // Helper function to create a button with a checkmark if active
         createButton := func(label, settingType, settingValue, currentValue string) *telegram.KeyboardButtonCallback {
                 text := label
@@ -85,12 +85,47 @@ func SettingsKeyboard(playMode, adminMode string) *telegram.ReplyInlineMarkup {
                 createButton("Everyone", "admin", cache.Everyone, adminMode),
         )
 
+        // Quality Section
+        keyboard.AddRow(telegram.Button.Data("🎚 Quality", "settings_xxx_none"))
+        keyboard.AddRow(
+                createButton("Low", "quality", config.QualityLow, quality),
+                createButton("Standard", "quality", config.QualityStandard, quality),
+        )
+        keyboard.AddRow(
+                createButton("High", "quality", config.QualityHigh, quality),
+                createButton("Best", "quality", config.QualityBest, quality),
+        )
+
         // Close button
         keyboard.AddRow(CloseBtn)
 
         return keyboard.Build()
 }
 
+// EffectsKeyboard creates an inline keyboard for picking an audio effect, marking the active one.
+func EffectsKeyboard(current string) *telegram.ReplyInlineMarkup {
+        keyboard := telegram.NewKeyboard()
+
+        var row []telegram.KeyboardButton
+        for _, effect := range EffectPresets {
+                text := effect.Label
+                if effect.Name == current {
+                        text += " ✅"
+                }
+                row = append(row, telegram.Button.Data(text, "effect_"+effect.Name))
+                if len(row) == 2 {
+                        keyboard.AddRow(row...)
+                        row = nil
+                }
+        }
+        if len(row) > 0 {
+                keyboard.AddRow(row...)
+        }
+
+        keyboard.AddRow(telegram.Button.Data("✖️ Off", "effect_off"), CloseBtn)
+        return keyboard.Build()
+}
+
 // HelpMenuKeyboard creates and returns an inline keyboard with buttons for navigating the help menu.
 func HelpMenuKeyboard() *telegram.ReplyInlineMarkup {
         keyboard := telegram.NewKeyboard().
@@ -113,6 +148,7 @@ func BackHelpMenuKeyboard() *telegram.ReplyInlineMarkup {
 
 // ControlButtons creates and returns an inline keyboard with playback control buttons, customized based on the current mode.
 // The 'mode' parameter can be "play", "pause", "resume", "mute", or "unmute" to display the relevant controls.
+// The seek and volume buttons are shown while a track is playing.
 func ControlButtons(mode string) *telegram.ReplyInlineMarkup {
         skipBtn := telegram.Button.Data("‣‣I", "play_skip")
         stopBtn := telegram.Button.Data("▢", "play_stop")
@@ -121,16 +157,21 @@ func ControlButtons(mode string) *telegram.ReplyInlineMarkup {
         muteBtn := telegram.Button.Data("🔇", "play_mute")
         unmuteBtn := telegram.Button.Data("🔊", "play_unmute")
         addToPlaylistBtn := telegram.Button.Data("➕ Playlist", "play_add_to_list")
+        volumeDownBtn := telegram.Button.Data("🔉 −", "play_voldown")
+        volumeUpBtn := telegram.Button.Data("🔊 +", "play_volup")
+        seekBackBtn := telegram.Button.Data("⏪ 10s", "play_seekback")
+        replayBtn := telegram.Button.Data("↺", "play_replay")
+        seekForwardBtn := telegram.Button.Data("⏩ 10s", "play_seekfwd")
 
         var keyboard *telegram.KeyboardBuilder
 
         switch mode {
         case "play":
-                keyboard = telegram.NewKeyboard().AddRow(skipBtn, stopBtn, pauseBtn, resumeBtn).AddRow(addToPlaylistBtn, CloseBtn)
+                keyboard = telegram.NewKeyboard().AddRow(skipBtn, stopBtn, pauseBtn, resumeBtn).AddRow(seekBackBtn, replayBtn, seekForwardBtn).AddRow(volumeDownBtn, volumeUpBtn).AddRow(addToPlaylistBtn, CloseBtn)
         case "pause":
                 keyboard = telegram.NewKeyboard().AddRow(skipBtn, stopBtn, resumeBtn).AddRow(CloseBtn)
         case "resume":
-                keyboard = telegram.NewKeyboard().AddRow(skipBtn, stopBtn, pauseBtn).AddRow(CloseBtn)
+                keyboard = telegram.NewKeyboard().AddRow(skipBtn, stopBtn, pauseBtn).AddRow(seekBackBtn, replayBtn, seekForwardBtn).AddRow(volumeDownBtn, volumeUpBtn).AddRow(CloseBtn)
         case "mute":
                 keyboard = telegram.NewKeyboard().AddRow(skipBtn, stopBtn, unmuteBtn).AddRow(CloseBtn)
         case "unmute":
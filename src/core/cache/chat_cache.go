@@ -16,6 +16,7 @@ import (
 type ChatData struct {
 	IsActive bool
 	Queue    []*CachedTrack
+	Playback PlaybackState
 }
 
 // ChatCacher is a thread-safe cache that manages music queues for multiple chats.
@@ -39,7 +40,7 @@ func (c *ChatCacher) AddSong(chatID int64, song *CachedTrack) *CachedTrack {
 
 	data, ok := c.chatCache[chatID]
 	if !ok {
-		data = &ChatData{IsActive: true, Queue: []*CachedTrack{}}
+		data = &ChatData{IsActive: true, Queue: []*CachedTrack{}, Playback: DefaultPlaybackState()}
 		c.chatCache[chatID] = data
 	}
 
@@ -107,7 +108,7 @@ func (c *ChatCacher) SetActive(chatID int64, active bool) {
 
 	data, ok := c.chatCache[chatID]
 	if !ok {
-		data = &ChatData{Queue: []*CachedTrack{}}
+		data = &ChatData{Queue: []*CachedTrack{}, Playback: DefaultPlaybackState()}
 		c.chatCache[chatID] = data
 	}
 	data.IsActive = active
@@ -226,3 +227,24 @@ func (c *ChatCacher) GetTrackIfExists(chatID int64, trackID string) *CachedTrack
 
 // ChatCache is the global chat cacher.
 var ChatCache = NewChatCacher()
+
+// ReplaceFilePath points every queued track of a chat that uses oldPath at newPath instead.
+// It returns the number of tracks that were updated.
+func (c *ChatCacher) ReplaceFilePath(chatID int64, oldPath, newPath string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, ok := c.chatCache[chatID]
+	if !ok {
+		return 0
+	}
+
+	updated := 0
+	for _, track := range data.Queue {
+		if track.FilePath == oldPath {
+			track.FilePath = newPath
+			updated++
+		}
+	}
+	return updated
+}
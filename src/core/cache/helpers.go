@@ -9,8 +9,11 @@
 package cache
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"strconv"
+	"strings"
 )
 
 // SecToMin converts a duration in seconds to a formatted string (MM:SS or HH:MM:SS).
@@ -30,3 +33,26 @@ func SecToMin(seconds int) string {
 	}
 	return fmt.Sprintf("%d:%02d", minutes, secs)
 }
+
+// MinToSec parses a position written as seconds, MM:SS or HH:MM:SS and returns it in seconds.
+// It is the inverse of SecToMin.
+func MinToSec(value string) (int, error) {
+	parts := strings.Split(strings.TrimSpace(value), ":")
+	if len(parts) > 3 {
+		return 0, errors.New("too many fields in time value")
+	}
+
+	seconds := 0
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid time value %q", value)
+		}
+		// Every field after the first is a minute or second field and must stay below 60.
+		if i > 0 && n >= 60 {
+			return 0, fmt.Errorf("invalid time value %q", value)
+		}
+		seconds = seconds*60 + n
+	}
+	return seconds, nil
+}
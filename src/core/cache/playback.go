@@ -0,0 +1,100 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package cache
+
+import "time"
+
+// PlaybackState holds everything that shapes the ffmpeg command of a chat's stream.
+// Every restart, whether for a seek, a speed change or an effect, rebuilds the command from it.
+type PlaybackState struct {
+	Offset    int     // Offset is the track position, in seconds, the current ffmpeg process started from.
+	Speed     float64 // Speed is the playback speed, 1 for normal speed.
+	Volume    int     // Volume is the stream volume in percent, 100 for unchanged.
+	Effect    string  // Effect is the name of the active audio effect, empty for none.
+	TrimStart int     // TrimStart is the track position, in seconds, playback starts from.
+	TrimEnd   int     // TrimEnd is the track position, in seconds, playback stops at, 0 for the end of the track.
+
+	// RepeatStart and RepeatEnd mark the section repeated by an A–B loop. RepeatEnd is 0 when no section repeats.
+	RepeatStart int
+	RepeatEnd   int
+
+	// LoudnessTarget is the loudness, in LUFS, the stream is normalized to, 0 for no normalization.
+	// Loudness holds the track's measured loudness for linear normalization, or nil to normalize dynamically.
+	LoudnessTarget int
+	Loudness       *Loudness
+
+	// Fade mixes the end of the previous track into the start of this one, nil for a plain start.
+	// Overlay mixes a jingle or an announcement into the start of the track, nil for none.
+	// Both only apply to the first process of a track, so they are never stored.
+	Fade    *Fade
+	Overlay *Overlay
+
+	// Screen sends the video as a screen share, which leaves the camera slot free.
+	Screen bool
+
+	// Lead is how many seconds of the stream an intro clip plays before the track starts.
+	Lead float64
+
+	// FadeUntil is when a fade-out that starts with each stream reaches silence, zero for no fade-out.
+	// It is a deadline, so a seek or track change during the fade keeps fading towards the same moment.
+	FadeUntil time.Time
+}
+
+// Overlay is a short clip, such as a jingle or a spoken announcement, played at the start of a track.
+type Overlay struct {
+	FilePath string  // FilePath is the clip's file or URL.
+	Intro    bool    // Intro plays the clip before the track instead of over it.
+	Length   float64 // Length is the clip's length in seconds.
+}
+
+// Fade describes the tail of the previous track that a crossfade mixes into the next one.
+type Fade struct {
+	FilePath string // FilePath is the previous track's file or stream URL.
+	Start    int    // Start is the previous track's position, in seconds, the tail starts at.
+	End      int    // End is the previous track's position, in seconds, the tail ends at.
+}
+
+// Loudness holds the values measured by the analysis pass of ffmpeg's loudnorm filter.
+type Loudness struct {
+	InputI      float64 `bson:"input_i"`
+	InputTP     float64 `bson:"input_tp"`
+	InputLRA    float64 `bson:"input_lra"`
+	InputThresh float64 `bson:"input_thresh"`
+}
+
+// DefaultPlaybackState returns the state of a chat that has not changed anything.
+func DefaultPlaybackState() PlaybackState {
+	return PlaybackState{Speed: 1, Volume: 100}
+}
+
+// GetPlayback returns a copy of a chat's playback state, or the default state if the chat has no queue.
+func (c *ChatCacher) GetPlayback(chatID int64) PlaybackState {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	data, ok := c.chatCache[chatID]
+	if !ok {
+		return DefaultPlaybackState()
+	}
+	return data.Playback
+}
+
+// UpdatePlayback changes a chat's playback state in place.
+// It returns false if the chat has no queue.
+func (c *ChatCacher) UpdatePlayback(chatID int64, update func(state *PlaybackState)) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, ok := c.chatCache[chatID]
+	if !ok {
+		return false
+	}
+	update(&data.Playback)
+	return true
+}
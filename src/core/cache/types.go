@@ -23,6 +23,9 @@ type CachedTrack struct {
 	Views     string `json:"views"`
 	IsVideo   bool   `json:"is_video"`
 	Platform  string `json:"platform"`
+	StartAt   int    `json:"start_at"` // StartAt is the track position, in seconds, playback starts from.
+	EndAt     int    `json:"end_at"`   // EndAt is the track position, in seconds, playback stops at, 0 for the end of the track.
+	Screen    bool   `json:"screen"`   // Screen sends a video track as a screen share instead of a camera feed.
 }
 
 // TrackInfo holds detailed information about a specific track, including its CDN URL, cover art, and lyrics.
@@ -0,0 +1,39 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package db
+
+import (
+	"context"
+	"errors"
+
+	"ashokshau/tgmusic/src/core/cache"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// GetLoudness retrieves the measured loudness of a track.
+// It returns nil without an error if the track has not been analyzed yet.
+func (db *Database) GetLoudness(ctx context.Context, trackID string) (*cache.Loudness, error) {
+	var loudness cache.Loudness
+	err := db.loudnessDB.FindOne(ctx, bson.M{"_id": trackID}).Decode(&loudness)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &loudness, nil
+}
+
+// SetLoudness stores the measured loudness of a track so it is analyzed only once.
+func (db *Database) SetLoudness(ctx context.Context, trackID string, loudness cache.Loudness) error {
+	_, err := db.loudnessDB.ReplaceOne(ctx, bson.M{"_id": trackID}, loudness, options.Replace().SetUpsert(true))
+	return err
+}
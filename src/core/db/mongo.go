@@ -32,6 +32,7 @@ type Database struct {
 	userDB       *mongo.Collection
 	botDB        *mongo.Collection
 	playlistDB   *mongo.Collection
+	loudnessDB   *mongo.Collection
 	chatCache    *cache.Cache[map[string]interface{}]
 	botCache     *cache.Cache[map[string]interface{}]
 	userCache    *cache.Cache[map[string]interface{}]
@@ -59,6 +60,7 @@ func InitDatabase(ctx context.Context) error {
 		userDB:     db.Collection("users"),
 		botDB:      db.Collection("bot"),
 		playlistDB: db.Collection("playlists"),
+		loudnessDB: db.Collection("loudness"),
 		chatCache:  cache.NewCache[map[string]interface{}](20 * time.Minute),
 		botCache:   cache.NewCache[map[string]interface{}](20 * time.Minute),
 		userCache:  cache.NewCache[map[string]interface{}](20 * time.Minute),
@@ -280,6 +282,33 @@ func (db *Database) ClearAllAssistants(ctx context.Context) (int64, error) {
 	return result.ModifiedCount, nil
 }
 
+// CountAssistantChats returns how many chats are assigned to each assistant.
+func (db *Database) CountAssistantChats(ctx context.Context) (map[string]int, error) {
+	cursor, err := db.chatDB.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"assistant": bson.M{"$exists": true, "$ne": ""}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$assistant", "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		log.Printf("[DB] Error counting assistant chats: %v", err)
+		return nil, err
+	}
+	defer func(cursor *mongo.Cursor, ctx context.Context) {
+		_ = cursor.Close(ctx)
+	}(cursor, ctx)
+
+	counts := make(map[string]int)
+	for cursor.Next(ctx) {
+		var group struct {
+			Assistant string `bson:"_id"`
+			Count     int    `bson:"count"`
+		}
+		if err := cursor.Decode(&group); err == nil {
+			counts[group.Assistant] = group.Count
+		}
+	}
+	return counts, cursor.Err()
+}
+
 // SetUserLang sets the language for a given user.
 func (db *Database) SetUserLang(ctx context.Context, userID int64, lang string) error {
 	return db.updateUserField(ctx, userID, "language", lang)
@@ -348,6 +377,183 @@ func (db *Database) SetRtmpUrl(ctx context.Context, chatID int64, url string) er
 	return db.updateChatField(ctx, chatID, "rtmp_url", url)
 }
 
+// GetQuality retrieves the quality profile name for a chat.
+// It returns an empty string if the chat uses the global default.
+func (db *Database) GetQuality(ctx context.Context, chatID int64) string {
+	chat, _ := db.getChat(ctx, chatID)
+	if chat == nil {
+		return ""
+	}
+	if val, ok := chat["quality"].(string); ok {
+		return val
+	}
+	return ""
+}
+
+// SetQuality sets the quality profile name for a given chat.
+func (db *Database) SetQuality(ctx context.Context, chatID int64, quality string) error {
+	return db.updateChatField(ctx, chatID, "quality", quality)
+}
+
+// GetVolume retrieves the stream volume for a chat in percent.
+// It returns 100 if no volume is set.
+func (db *Database) GetVolume(ctx context.Context, chatID int64) int {
+	chat, _ := db.getChat(ctx, chatID)
+	if chat == nil {
+		return 100
+	}
+	if val, ok := chat["volume"].(int32); ok {
+		return int(val)
+	}
+	return 100
+}
+
+// SetVolume sets the stream volume for a given chat in percent.
+func (db *Database) SetVolume(ctx context.Context, chatID int64, volume int) error {
+	return db.updateChatField(ctx, chatID, "volume", int32(volume))
+}
+
+// GetLoudnessTarget retrieves the loudness normalization target for a chat in LUFS.
+// It returns 0 if normalization is disabled.
+func (db *Database) GetLoudnessTarget(ctx context.Context, chatID int64) int {
+	chat, _ := db.getChat(ctx, chatID)
+	if chat == nil {
+		return 0
+	}
+	if val, ok := chat["loudness_target"].(int32); ok {
+		return int(val)
+	}
+	return 0
+}
+
+// SetLoudnessTarget sets the loudness normalization target for a given chat in LUFS, or disables it with 0.
+func (db *Database) SetLoudnessTarget(ctx context.Context, chatID int64, target int) error {
+	return db.updateChatField(ctx, chatID, "loudness_target", int32(target))
+}
+
+// GetCrossfade retrieves the crossfade length between tracks for a chat in seconds.
+// It returns 0 if crossfading is disabled.
+func (db *Database) GetCrossfade(ctx context.Context, chatID int64) int {
+	chat, _ := db.getChat(ctx, chatID)
+	if chat == nil {
+		return 0
+	}
+	if val, ok := chat["crossfade"].(int32); ok {
+		return int(val)
+	}
+	return 0
+}
+
+// SetCrossfade sets the crossfade length between tracks for a given chat in seconds, or disables it with 0.
+func (db *Database) SetCrossfade(ctx context.Context, chatID int64, seconds int) error {
+	return db.updateChatField(ctx, chatID, "crossfade", int32(seconds))
+}
+
+// GetJingle retrieves the jingle source of a chat, a Telegram message link or an audio URL.
+// It returns an empty string if no jingle is set.
+func (db *Database) GetJingle(ctx context.Context, chatID int64) string {
+	chat, _ := db.getChat(ctx, chatID)
+	if chat == nil {
+		return ""
+	}
+	if val, ok := chat["jingle"].(string); ok {
+		return val
+	}
+	return ""
+}
+
+// SetJingle sets the jingle source for a given chat, or removes it with an empty string.
+func (db *Database) SetJingle(ctx context.Context, chatID int64, source string) error {
+	return db.updateChatField(ctx, chatID, "jingle", source)
+}
+
+// GetJingleIntro reports whether a chat plays its jingle or announcement before each track instead of over it.
+func (db *Database) GetJingleIntro(ctx context.Context, chatID int64) bool {
+	chat, _ := db.getChat(ctx, chatID)
+	if chat == nil {
+		return false
+	}
+	if val, ok := chat["jingle_intro"].(bool); ok {
+		return val
+	}
+	return false
+}
+
+// SetJingleIntro sets whether a given chat plays its jingle or announcement before each track.
+func (db *Database) SetJingleIntro(ctx context.Context, chatID int64, intro bool) error {
+	return db.updateChatField(ctx, chatID, "jingle_intro", intro)
+}
+
+// GetAnnounce reports whether a chat announces each track with a spoken clip.
+func (db *Database) GetAnnounce(ctx context.Context, chatID int64) bool {
+	chat, _ := db.getChat(ctx, chatID)
+	if chat == nil {
+		return false
+	}
+	if val, ok := chat["announce"].(bool); ok {
+		return val
+	}
+	return false
+}
+
+// SetAnnounce sets whether a given chat announces each track with a spoken clip.
+func (db *Database) SetAnnounce(ctx context.Context, chatID int64, announce bool) error {
+	return db.updateChatField(ctx, chatID, "announce", announce)
+}
+
+// GetScreenShare reports whether a chat sends video tracks as a screen share instead of a camera feed.
+func (db *Database) GetScreenShare(ctx context.Context, chatID int64) bool {
+	chat, _ := db.getChat(ctx, chatID)
+	if chat == nil {
+		return false
+	}
+	if val, ok := chat["screen_share"].(bool); ok {
+		return val
+	}
+	return false
+}
+
+// SetScreenShare sets whether a given chat sends video tracks as a screen share.
+func (db *Database) SetScreenShare(ctx context.Context, chatID int64, screen bool) error {
+	return db.updateChatField(ctx, chatID, "screen_share", screen)
+}
+
+// GetIdlePolicy retrieves what a chat does when nobody is listening: "off", "pause", "stop" or "leave".
+// It returns "off" by default.
+func (db *Database) GetIdlePolicy(ctx context.Context, chatID int64) string {
+	chat, _ := db.getChat(ctx, chatID)
+	if chat == nil {
+		return "off"
+	}
+	if val, ok := chat["idle_policy"].(string); ok {
+		return val
+	}
+	return "off"
+}
+
+// SetIdlePolicy sets what a chat does when nobody is listening.
+func (db *Database) SetIdlePolicy(ctx context.Context, chatID int64, policy string) error {
+	return db.updateChatField(ctx, chatID, "idle_policy", policy)
+}
+
+// GetIdleTimeout retrieves how many minutes a chat's voice chat may have no listeners before its idle policy applies.
+// It returns 5 by default.
+func (db *Database) GetIdleTimeout(ctx context.Context, chatID int64) int {
+	chat, _ := db.getChat(ctx, chatID)
+	if chat == nil {
+		return 5
+	}
+	if val, ok := chat["idle_timeout"].(int32); ok {
+		return int(val)
+	}
+	return 5
+}
+
+// SetIdleTimeout sets how many minutes a chat's voice chat may have no listeners before its idle policy applies.
+func (db *Database) SetIdleTimeout(ctx context.Context, chatID int64, minutes int) error {
+	return db.updateChatField(ctx, chatID, "idle_timeout", int32(minutes))
+}
+
 // ----------------- AUTH USERS -----------------
 
 // AddAuthUser adds a user to the list of authorized users for a chat.
@@ -467,6 +673,108 @@ func (db *Database) SetLoggerStatus(ctx context.Context, botID int64, status boo
 	return err
 }
 
+// GetDefaultQuality retrieves the global quality profile name set by the devs for a bot.
+// It returns an empty string if none is set.
+func (db *Database) GetDefaultQuality(ctx context.Context, botID int64) string {
+	key := toKey(botID)
+	cached, ok := db.botCache.Get(key)
+	if ok {
+		if v, ok := cached["quality"].(string); ok {
+			return v
+		}
+	}
+
+	var data map[string]interface{}
+	_ = db.botDB.FindOne(ctx, bson.M{"_id": botID}).Decode(&data)
+
+	quality := ""
+	if val, ok := data["quality"].(string); ok {
+		quality = val
+	}
+
+	db.botCacheMux.Lock()
+	defer db.botCacheMux.Unlock()
+	newCached := map[string]interface{}{}
+	for k, v := range cached {
+		newCached[k] = v
+	}
+	newCached["quality"] = quality
+	db.botCache.Set(key, newCached)
+	return quality
+}
+
+// SetDefaultQuality sets the global quality profile name for a bot.
+func (db *Database) SetDefaultQuality(ctx context.Context, botID int64, quality string) error {
+	_, err := db.botDB.UpdateOne(ctx,
+		bson.M{"_id": botID},
+		bson.M{"$set": bson.M{"quality": quality}},
+		options.UpdateOne().SetUpsert(true),
+	)
+	if err == nil {
+		db.botCacheMux.Lock()
+		defer db.botCacheMux.Unlock()
+		cached, _ := db.botCache.Get(toKey(botID))
+		newCached := map[string]interface{}{}
+		for k, v := range cached {
+			newCached[k] = v
+		}
+		newCached["quality"] = quality
+		db.botCache.Set(toKey(botID), newCached)
+	}
+	return err
+}
+
+// GetPrivateCalls retrieves how the devs set the assistants to handle private calls for a bot.
+// It returns an empty string if they have not set it.
+func (db *Database) GetPrivateCalls(ctx context.Context, botID int64) string {
+	key := toKey(botID)
+	cached, ok := db.botCache.Get(key)
+	if ok {
+		if v, ok := cached["private_calls"].(string); ok {
+			return v
+		}
+	}
+
+	var data map[string]interface{}
+	_ = db.botDB.FindOne(ctx, bson.M{"_id": botID}).Decode(&data)
+
+	mode := ""
+	if val, ok := data["private_calls"].(string); ok {
+		mode = val
+	}
+
+	db.botCacheMux.Lock()
+	defer db.botCacheMux.Unlock()
+	newCached := map[string]interface{}{}
+	for k, v := range cached {
+		newCached[k] = v
+	}
+	newCached["private_calls"] = mode
+	db.botCache.Set(key, newCached)
+	return mode
+}
+
+// SetPrivateCalls sets how the assistants handle private calls for a bot.
+func (db *Database) SetPrivateCalls(ctx context.Context, botID int64, mode string) error {
+	_, err := db.botDB.UpdateOne(ctx,
+		bson.M{"_id": botID},
+		bson.M{"$set": bson.M{"private_calls": mode}},
+		options.UpdateOne().SetUpsert(true),
+	)
+	if err == nil {
+		db.botCacheMux.Lock()
+		defer db.botCacheMux.Unlock()
+		cached, _ := db.botCache.Get(toKey(botID))
+		newCached := map[string]interface{}{}
+		for k, v := range cached {
+			newCached[k] = v
+		}
+		newCached["private_calls"] = mode
+		db.botCache.Set(toKey(botID), newCached)
+	}
+	return err
+}
+
 // ----------------- USERS -----------------
 
 // AddUser adds a new user to the database if they do not already exist.
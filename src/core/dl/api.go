@@ -13,14 +13,33 @@ import (
         "encoding/json"
         "errors"
         "fmt"
+        "io"
         "net/http"
         "net/url"
         "regexp"
+        "strconv"
         "strings"
 
+        "ashokshau/tgmusic/src/config"
         "ashokshau/tgmusic/src/core/cache"
 )
 
+// ApiKeyHeader is the request header that carries config.Conf.ApiKey to the API gateway.
+const ApiKeyHeader = "X-API-Key"
+
+// ApiResponse is the response schema of the API gateway.
+// The gateway is queried with `GET {API_URL}/?url=<escaped track URL>` and must answer with this JSON object.
+// See README.md in this package for the full contract.
+type ApiResponse struct {
+        Status    bool              `json:"status"`              // Status is false when the track could not be resolved.
+        Message   string            `json:"message,omitempty"`   // Message explains a false status.
+        Title     string            `json:"title"`               // Title is the track title.
+        Thumbnail string            `json:"thumbnail"`           // Thumbnail is the cover art URL.
+        Duration  int               `json:"duration"`            // Duration is the track length in seconds.
+        Audio     string            `json:"audio"`               // Audio is a direct, downloadable audio URL.
+        Videos    map[string]string `json:"videos,omitempty"`    // Videos maps a height (e.g. "720") to a direct video URL.
+}
+
 // ApiData provides a unified interface for fetching track and playlist information from various music platforms via an API gateway.
 type ApiData struct {
         Query    string
@@ -35,14 +54,21 @@ var apiPatterns = map[string]*regexp.Regexp{
 }
 
 // NewApiData creates and initializes a new ApiData instance with the provided query.
+// The gateway URL and key are taken from config.Conf.ApiUrl and config.Conf.ApiKey.
 func NewApiData(query string) *ApiData {
         return &ApiData{
-                Query:  strings.TrimSpace(query),
-                ApiUrl: "https://yt-dl.officialhectormanuel.workers.dev",
+                Query:    strings.TrimSpace(query),
+                ApiUrl:   strings.TrimRight(config.Conf.ApiUrl, "/"),
+                APIKey:   config.Conf.ApiKey,
                 Patterns: apiPatterns,
         }
 }
 
+// IsApiConfigured reports whether an API gateway URL is configured.
+func IsApiConfigured() bool {
+        return strings.TrimSpace(config.Conf.ApiUrl) != ""
+}
+
 // IsValid checks if the query is a valid URL for any of the supported platforms.
 // It returns true if the URL matches a known pattern, and false otherwise.
 func (a *ApiData) IsValid() bool {
@@ -63,46 +89,124 @@ func (a *ApiData) IsValid() bool {
         return false
 }
 
-// GetTrack retrieves detailed information for a single track from the API.
-// It returns a cache.TrackInfo object or an error if the request fails.
-func (a *ApiData) GetTrack(ctx context.Context) (cache.TrackInfo, error) {
+// fetch queries the API gateway for the current query and decodes the response.
+// It returns an error if the gateway is unreachable, rejects the key or reports a false status.
+func (a *ApiData) fetch(ctx context.Context) (*ApiResponse, error) {
+        if a.ApiUrl == "" {
+                return nil, NewError(ErrBackendDown, errors.New("the API gateway is not configured"))
+        }
+
         fullURL := fmt.Sprintf("%s/?url=%s", a.ApiUrl, url.QueryEscape(a.Query))
-        resp, err := http.Get(fullURL)
+        headers := map[string]string{"Accept": "application/json"}
+        if a.APIKey != "" {
+                headers[ApiKeyHeader] = a.APIKey
+        }
+
+        resp, err := sendRequest(ctx, http.MethodGet, fullURL, nil, headers)
         if err != nil {
-                return cache.TrackInfo{}, fmt.Errorf("the GetTrack request failed: %w", err)
+                return nil, classifyError(fmt.Errorf("the API request failed: %w", err), ErrBackendDown)
         }
-        defer resp.Body.Close()
+        defer func(Body io.ReadCloser) {
+                _ = Body.Close()
+        }(resp.Body)
 
-        if resp.StatusCode != http.StatusOK {
-                return cache.TrackInfo{}, fmt.Errorf("unexpected status code while fetching the track: %s", resp.Status)
+        var result ApiResponse
+        if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+                if resp.StatusCode != http.StatusOK {
+                        return nil, NewError(apiStatusKind(resp.StatusCode), fmt.Errorf("unexpected status code from the API: %s", resp.Status))
+                }
+                return nil, NewError(ErrBackendDown, fmt.Errorf("failed to decode the API response: %w", err))
         }
 
-        var result struct {
-                Status    bool   `json:"status"`
-                Title     string `json:"title"`
-                Thumbnail string `json:"thumbnail"`
-                Audio     string `json:"audio"`
+        if resp.StatusCode != http.StatusOK || !result.Status {
+                if result.Message == "" {
+                        result.Message = resp.Status
+                }
+                err := fmt.Errorf("the API could not resolve the track: %s", result.Message)
+                if kind := classifyMessage(result.Message); kind != ErrUnknown {
+                        return nil, NewError(kind, err)
+                }
+                return nil, NewError(apiStatusKind(resp.StatusCode), err)
         }
 
-        if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-                return cache.TrackInfo{}, fmt.Errorf("failed to decode the response: %w", err)
+        if result.Audio == "" {
+                return nil, NewError(ErrNotFound, errors.New("the API response has no audio URL"))
         }
 
-        if !result.Status {
-                return cache.TrackInfo{}, errors.New("API returned false status")
+        return &result, nil
+}
+
+// apiStatusKind maps an HTTP status code returned by the API gateway onto an error kind.
+func apiStatusKind(status int) ErrorKind {
+        switch {
+        case status == http.StatusTooManyRequests:
+                return ErrRateLimited
+        case status == http.StatusUnauthorized || status == http.StatusForbidden || status >= 500:
+                return ErrBackendDown
+        case status == http.StatusUnavailableForLegalReasons:
+                return ErrGeoBlocked
+        default:
+                return ErrNotFound
+        }
+}
+
+// GetTrack retrieves detailed information for a single track from the API.
+// It returns a cache.TrackInfo object or an error if the request fails.
+func (a *ApiData) GetTrack(ctx context.Context) (cache.TrackInfo, error) {
+        result, err := a.fetch(ctx)
+        if err != nil {
+                return cache.TrackInfo{}, err
         }
 
         trackInfo := cache.TrackInfo{
                 URL:      a.Query,
                 CdnURL:   result.Audio,
                 Name:     result.Title,
+                TC:       NewYouTubeData(a.Query).extractVideoID(a.Query),
                 Cover:    result.Thumbnail,
+                Duration: result.Duration,
                 Platform: "youtube",
         }
 
         return trackInfo, nil
 }
 
+// GetVideoURL asks the API for a direct video URL of the query.
+// It picks the tallest advertised height that does not exceed maxHeight, then the shortest one above it,
+// and falls back to the audio URL.
+func (a *ApiData) GetVideoURL(ctx context.Context, maxHeight int) (string, error) {
+        result, err := a.fetch(ctx)
+        if err != nil {
+                return "", err
+        }
+
+        var bestLink, overLink string
+        bestHeight, overHeight := 0, 0
+        for key, link := range result.Videos {
+                height, err := strconv.Atoi(key)
+                if err != nil || link == "" {
+                        continue
+                }
+                if height <= maxHeight && height > bestHeight {
+                        bestHeight, bestLink = height, link
+                } else if height > maxHeight && (overHeight == 0 || height < overHeight) {
+                        overHeight, overLink = height, link
+                }
+        }
+        if bestLink != "" {
+                return bestLink, nil
+        }
+        if overLink != "" {
+                return overLink, nil
+        }
+        for _, link := range result.Videos {
+                if link != "" {
+                        return link, nil
+                }
+        }
+        return result.Audio, nil
+}
+
 // GetInfo retrieves metadata for a track or playlist from the API.
 // It returns a PlatformTracks object or an error if the request fails.
 func (a *ApiData) GetInfo(ctx context.Context) (cache.PlatformTracks, error) {
@@ -133,15 +237,15 @@ func (a *ApiData) Search(ctx context.Context) (cache.PlatformTracks, error) {
         if a.IsValid() {
                 return a.GetInfo(ctx)
         }
-        return cache.PlatformTracks{}, errors.New("search is handled by youtube module")
+        return cache.PlatformTracks{}, NewError(ErrNotFound, errors.New("search is handled by youtube module"))
 }
 
 // downloadTrack downloads a track using the API.
 // It returns the file path of the downloaded track or an error if the download fails.
-func (a *ApiData) downloadTrack(ctx context.Context, info cache.TrackInfo, video bool) (string, error) {
+func (a *ApiData) downloadTrack(ctx context.Context, info cache.TrackInfo, video bool, _ config.QualityProfile) (string, error) {
         downloader, err := NewDownload(ctx, info)
         if err != nil {
-                return "", fmt.Errorf("failed to initialize the download: %w", err)
+                return "", classifyError(fmt.Errorf("failed to initialize the download: %w", err), ErrBackendDown)
         }
 
         return downloader.Process()
@@ -0,0 +1,270 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package dl
+
+import (
+	"bufio"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+)
+
+// maxCookieFailures is the number of consecutive cookie-related yt-dlp failures after which a cookie is retired.
+const maxCookieFailures = 3
+
+// cookieErrorMarkers are yt-dlp stderr fragments that indicate the cookie itself was rejected.
+var cookieErrorMarkers = []string{
+	"sign in to confirm",
+	"confirm you're not a bot",
+	"confirm you’re not a bot",
+	"cookies are no longer valid",
+	"use --cookies-from-browser or --cookies",
+	"this helps protect our community",
+	"account has been terminated",
+}
+
+// CookieStatus holds the health counters of a single cookies file.
+type CookieStatus struct {
+	Path                string
+	Successes           int
+	Failures            int
+	ConsecutiveFailures int
+	LastError           string
+	LastUsed            time.Time
+	Retired             bool
+}
+
+// CookiePool tracks the health of the cookies files used by yt-dlp and hands out healthy ones.
+type CookiePool struct {
+	mu      sync.Mutex
+	cookies map[string]*CookieStatus
+	order   []string
+}
+
+// Cookies is the global cookie pool.
+var Cookies = &CookiePool{cookies: make(map[string]*CookieStatus)}
+
+// syncLocked registers cookies files that were added to config.Conf.CookiesPath since the last call.
+// The caller must hold p.mu.
+func (p *CookiePool) syncLocked() {
+	for _, path := range config.CookiePaths() {
+		if _, ok := p.cookies[path]; !ok {
+			p.cookies[path] = &CookieStatus{Path: path}
+			p.order = append(p.order, path)
+		}
+	}
+}
+
+// Pick returns a random healthy cookies file, or an empty string if none is available.
+func (p *CookiePool) Pick() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.syncLocked()
+
+	var healthy []string
+	for _, path := range p.order {
+		if !p.cookies[path].Retired {
+			healthy = append(healthy, path)
+		}
+	}
+	if len(healthy) == 0 {
+		return ""
+	}
+
+	chosen := healthy[0]
+	if n, err := rand.Int(rand.Reader, big.NewInt(int64(len(healthy)))); err == nil {
+		chosen = healthy[n.Int64()]
+	} else {
+		log.Printf("Could not generate a random number: %v", err)
+	}
+
+	p.cookies[chosen].LastUsed = time.Now()
+	return chosen
+}
+
+// ReportSuccess records a successful download made with the given cookies file.
+func (p *CookiePool) ReportSuccess(path string) {
+	if path == "" {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if status, ok := p.cookies[path]; ok {
+		status.Successes++
+		status.ConsecutiveFailures = 0
+	}
+}
+
+// ReportFailure inspects yt-dlp's stderr and, if it points at the cookie, records a failure.
+// The cookie is retired after maxCookieFailures consecutive failures.
+// It returns true if the failure was attributed to the cookie.
+func (p *CookiePool) ReportFailure(path, stderr string) bool {
+	if path == "" || !IsCookieError(stderr) {
+		return false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	status, ok := p.cookies[path]
+	if !ok {
+		return false
+	}
+
+	status.Failures++
+	status.ConsecutiveFailures++
+	status.LastError = firstLine(stderr)
+	if !status.Retired && status.ConsecutiveFailures >= maxCookieFailures {
+		status.Retired = true
+		log.Printf("[Cookies] Retired %s after %d consecutive failures: %s", path, status.ConsecutiveFailures, status.LastError)
+	}
+	return true
+}
+
+// List returns a snapshot of all tracked cookies in the order they were added.
+func (p *CookiePool) List() []CookieStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.syncLocked()
+
+	list := make([]CookieStatus, 0, len(p.order))
+	for _, path := range p.order {
+		list = append(list, *p.cookies[path])
+	}
+	return list
+}
+
+// Count returns how many cookies files the pool tracks.
+func (p *CookiePool) Count() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.syncLocked()
+	return len(p.order)
+}
+
+// Add validates a Netscape cookies file, stores it in config.CookiesDir and adds it to the pool.
+// It returns the stored path.
+func (p *CookiePool) Add(srcPath string) (string, error) {
+	if err := validateCookiesFile(srcPath); err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(config.CookiesDir, 0750); err != nil {
+		return "", fmt.Errorf("failed to create the cookies directory: %w", err)
+	}
+
+	data, err := os.ReadFile(srcPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the cookies file: %w", err)
+	}
+
+	dst := filepath.Join(config.CookiesDir, generateUniqueName(".txt"))
+	if err := os.WriteFile(dst, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to save the cookies file: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	config.AddCookiePath(dst)
+	p.syncLocked()
+	return dst, nil
+}
+
+// Remove deletes the cookies file at the given 1-based position from the pool, the config and the disk.
+// It returns the removed path.
+func (p *CookiePool) Remove(index int) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.syncLocked()
+
+	if index < 1 || index > len(p.order) {
+		return "", fmt.Errorf("there is no cookie #%d", index)
+	}
+
+	path := p.order[index-1]
+	p.order = append(p.order[:index-1], p.order[index:]...)
+	delete(p.cookies, path)
+
+	config.RemoveCookiePath(path)
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		log.Printf("[Cookies] Failed to delete %s: %v", path, err)
+	}
+	return path, nil
+}
+
+// Reset clears the counters of the cookies file at the given 1-based position and puts it back into rotation.
+func (p *CookiePool) Reset(index int) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.syncLocked()
+
+	if index < 1 || index > len(p.order) {
+		return "", fmt.Errorf("there is no cookie #%d", index)
+	}
+
+	path := p.order[index-1]
+	p.cookies[path] = &CookieStatus{Path: path}
+	return path, nil
+}
+
+// IsCookieError reports whether yt-dlp's stderr indicates a sign-in or bot-check rejection.
+func IsCookieError(stderr string) bool {
+	lower := strings.ToLower(stderr)
+	for _, marker := range cookieErrorMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateCookiesFile checks that a file looks like a Netscape-format cookies file.
+func validateCookiesFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open the cookies file: %w", err)
+	}
+	defer func(f *os.File) {
+		_ = f.Close()
+	}(f)
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || (strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "#HttpOnly_")) {
+			continue
+		}
+		if len(strings.Split(line, "\t")) == 7 {
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read the cookies file: %w", err)
+	}
+	return errors.New("the file is not a Netscape cookies.txt")
+}
+
+// firstLine returns the first non-empty line of s.
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
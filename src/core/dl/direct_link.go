@@ -18,6 +18,7 @@ import (
 	"strconv"
 	"strings"
 
+	"ashokshau/tgmusic/src/config"
 	"ashokshau/tgmusic/src/core/cache"
 )
 
@@ -36,7 +37,7 @@ func (d *DirectLink) IsValid() bool {
 
 func (d *DirectLink) GetInfo(ctx context.Context) (cache.PlatformTracks, error) {
 	if !d.IsValid() {
-		return cache.PlatformTracks{}, errors.New("invalid url")
+		return cache.PlatformTracks{}, NewError(ErrNotFound, errors.New("invalid url"))
 	}
 
 	cmd := exec.CommandContext(ctx, "ffprobe",
@@ -48,7 +49,7 @@ func (d *DirectLink) GetInfo(ctx context.Context) (cache.PlatformTracks, error)
 
 	output, err := cmd.Output()
 	if err != nil {
-		return cache.PlatformTracks{}, fmt.Errorf("invalid or unplayable link: %w", err)
+		return cache.PlatformTracks{}, NewError(ErrNotFound, fmt.Errorf("invalid or unplayable link: %w", err))
 	}
 
 	var info cache.FFProbeFormat
@@ -103,7 +104,7 @@ func (d *DirectLink) GetTrack(ctx context.Context) (cache.TrackInfo, error) {
 		return cache.TrackInfo{}, err
 	}
 	if len(info.Results) == 0 {
-		return cache.TrackInfo{}, errors.New("no track found")
+		return cache.TrackInfo{}, NewError(ErrNotFound, errors.New("no track found"))
 	}
 
 	t := info.Results[0]
@@ -117,6 +118,6 @@ func (d *DirectLink) GetTrack(ctx context.Context) (cache.TrackInfo, error) {
 	}, nil
 }
 
-func (d *DirectLink) downloadTrack(_ context.Context, _ cache.TrackInfo, _ bool) (string, error) {
+func (d *DirectLink) downloadTrack(_ context.Context, _ cache.TrackInfo, _ bool, _ config.QualityProfile) (string, error) {
 	return d.Query, nil
 }
@@ -0,0 +1,126 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package dl
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrorKind classifies why a track could not be resolved or downloaded.
+type ErrorKind string
+
+const (
+	ErrUnknown          ErrorKind = "unknown"
+	ErrNotFound         ErrorKind = "not_found"
+	ErrAgeRestricted    ErrorKind = "age_restricted"
+	ErrGeoBlocked       ErrorKind = "geo_blocked"
+	ErrPrivate          ErrorKind = "private"
+	ErrLiveNotSupported ErrorKind = "live_not_supported"
+	ErrTooLong          ErrorKind = "too_long"
+	ErrRateLimited      ErrorKind = "rate_limited"
+	ErrBackendDown      ErrorKind = "backend_down"
+)
+
+// Error is a download failure with a kind that can be shown to users and the raw cause for the devs.
+type Error struct {
+	Kind ErrorKind
+	Err  error
+}
+
+// Error returns the raw cause of the failure.
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return string(e.Kind)
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the raw cause of the failure.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// NewError wraps err with the given kind.
+// Errors that already carry a kind are returned unchanged.
+func NewError(kind ErrorKind, err error) error {
+	if err == nil {
+		return nil
+	}
+	var dlErr *Error
+	if errors.As(err, &dlErr) {
+		return err
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+// classifyError wraps err with the kind its message points at, or with fallback if nothing matches.
+func classifyError(err error, fallback ErrorKind) error {
+	if err == nil {
+		return nil
+	}
+	if kind := classifyMessage(err.Error()); kind != ErrUnknown {
+		return NewError(kind, err)
+	}
+	return NewError(fallback, err)
+}
+
+// KindOf returns the kind of a download failure, or ErrUnknown for untyped errors.
+func KindOf(err error) ErrorKind {
+	var dlErr *Error
+	if errors.As(err, &dlErr) {
+		return dlErr.Kind
+	}
+	return ErrUnknown
+}
+
+// errorMarkers maps lower-cased fragments of yt-dlp, gateway and HTTP messages, and the HTTP status codes
+// they report, onto error kinds. The order matters: the first matching entry wins.
+var errorMarkers = []struct {
+	kind     ErrorKind
+	markers  []string
+	statuses []int
+}{
+	{ErrAgeRestricted, []string{"confirm your age", "age-restricted", "age restricted", "inappropriate for some users"}, nil},
+	{ErrPrivate, []string{"private video", "video is private", "members-only", "join this channel to get access"}, nil},
+	{ErrGeoBlocked, []string{"not available in your country", "blocked it in your country", "geo restricted", "geo-restricted", "geoblocked"}, []int{451}},
+	{ErrLiveNotSupported, []string{"live event", "is live", "live stream", "livestream", "premieres in", "premiere will begin"}, nil},
+	{ErrRateLimited, []string{"too many requests", "rate limit", "rate-limit", "confirm you're not a bot", "confirm you’re not a bot"}, []int{429}},
+	{ErrNotFound, []string{"video unavailable", "not found", "does not exist", "has been removed", "no video results", "no track found", "unable to extract the video id", "invalid url", "not supported"}, []int{404, 410}},
+	{ErrBackendDown, []string{"timed out", "timeout", "connection refused", "no such host", "not configured", "unauthorized"}, []int{401, 500, 502, 503, 504}},
+}
+
+// statusPattern finds the HTTP status code in messages such as yt-dlp's "HTTP Error 429" and
+// the bot's own "unexpected status code: 404", so digits in video IDs and URLs are never taken for one.
+var statusPattern = regexp.MustCompile(`(?:http error|status code[a-z ]*:)\s*(\d{3})`)
+
+// classifyMessage returns the kind a raw error message points at, or ErrUnknown.
+func classifyMessage(msg string) ErrorKind {
+	lower := strings.ToLower(msg)
+	status := 0
+	if m := statusPattern.FindStringSubmatch(lower); m != nil {
+		status, _ = strconv.Atoi(m[1])
+	}
+
+	for _, entry := range errorMarkers {
+		for _, marker := range entry.markers {
+			if strings.Contains(lower, marker) {
+				return entry.kind
+			}
+		}
+		for _, code := range entry.statuses {
+			if status == code {
+				return entry.kind
+			}
+		}
+	}
+	return ErrUnknown
+}
@@ -10,7 +10,9 @@ package dl
 
 import (
         "context"
+        "fmt"
 
+        "ashokshau/tgmusic/src/config"
         "ashokshau/tgmusic/src/core/cache"
 )
 
@@ -25,8 +27,8 @@ type MusicService interface {
         Search(ctx context.Context) (cache.PlatformTracks, error)
         // GetTrack fetches detailed information for a single track.
         GetTrack(ctx context.Context) (cache.TrackInfo, error)
-        // downloadTrack handles the download of a track.
-        downloadTrack(ctx context.Context, trackInfo cache.TrackInfo, video bool) (string, error)
+        // downloadTrack handles the download of a track at the given quality profile.
+        downloadTrack(ctx context.Context, trackInfo cache.TrackInfo, video bool, profile config.QualityProfile) (string, error)
 }
 
 // DownloaderWrapper provides a unified interface for music service interactions,
@@ -55,22 +57,36 @@ func (d *DownloaderWrapper) IsValid() bool {
 }
 
 // GetInfo retrieves metadata by delegating the call to the wrapped service.
+// Failures are returned as *Error.
 func (d *DownloaderWrapper) GetInfo(ctx context.Context) (cache.PlatformTracks, error) {
-        return d.Service.GetInfo(ctx)
+        info, err := d.Service.GetInfo(ctx)
+        return info, classifyError(err, ErrUnknown)
 }
 
 // Search performs a search by delegating the call to the wrapped service.
+// Failures are returned as *Error.
 func (d *DownloaderWrapper) Search(ctx context.Context) (cache.PlatformTracks, error) {
-        return d.Service.Search(ctx)
+        results, err := d.Service.Search(ctx)
+        return results, classifyError(err, ErrUnknown)
 }
 
 // GetTrack retrieves detailed track information by delegating the call to the wrapped service.
+// It rejects tracks longer than SONG_DURATION_LIMIT, and failures are returned as *Error.
 func (d *DownloaderWrapper) GetTrack(ctx context.Context) (cache.TrackInfo, error) {
-        return d.Service.GetTrack(ctx)
+        track, err := d.Service.GetTrack(ctx)
+        if err != nil {
+                return track, classifyError(err, ErrUnknown)
+        }
+
+        if limit := config.Conf.SongDurationLimit; limit > 0 && int64(track.Duration) > limit {
+                return track, NewError(ErrTooLong, fmt.Errorf("%s is %ds long, the limit is %ds", track.Name, track.Duration, limit))
+        }
+        return track, nil
 }
 
-// DownloadTrack downloads a track by delegating the call to the wrapped service.
-// It returns the file path of the downloaded track or an error if the download fails.
-func (d *DownloaderWrapper) DownloadTrack(ctx context.Context, info cache.TrackInfo, video bool) (string, error) {
-        return d.Service.downloadTrack(ctx, info, video)
+// DownloadTrack downloads a track at the given quality profile by delegating the call to the wrapped service.
+// It returns the file path of the downloaded track or an error of type *Error if the download fails.
+func (d *DownloaderWrapper) DownloadTrack(ctx context.Context, info cache.TrackInfo, video bool, profile config.QualityProfile) (string, error) {
+        filePath, err := d.Service.downloadTrack(ctx, info, video, profile)
+        return filePath, classifyError(err, ErrUnknown)
 }
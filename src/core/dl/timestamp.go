@@ -0,0 +1,68 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package dl
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"ashokshau/tgmusic/src/core/cache"
+)
+
+var timestampRegex = regexp.MustCompile(`^(?:(\d+)h)?(?:(\d+)m)?(?:(\d+)s?)?$`)
+
+// URLTimestamp returns the start position, in seconds, encoded in a link's t or start parameter, or 0 if it has none.
+// Both the query and the fragment are checked, so YouTube's ?t=1m30s and SoundCloud's #t=1:30 are understood.
+func URLTimestamp(rawURL string) int {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return 0
+	}
+
+	values := u.Query()
+	if fragment, err := url.ParseQuery(u.Fragment); err == nil {
+		for key, value := range fragment {
+			values[key] = append(values[key], value...)
+		}
+	}
+
+	for _, key := range []string{"t", "start"} {
+		if seconds, ok := parseTimestamp(values.Get(key)); ok {
+			return seconds
+		}
+	}
+	return 0
+}
+
+// parseTimestamp parses a timestamp written as seconds, 1h2m3s or 1:02:03.
+func parseTimestamp(value string) (int, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if strings.Contains(value, ":") {
+		seconds, err := cache.MinToSec(value)
+		return seconds, err == nil
+	}
+
+	match := timestampRegex.FindStringSubmatch(value)
+	if match == nil {
+		return 0, false
+	}
+
+	seconds := 0
+	for i, unit := range []int{3600, 60, 1} {
+		if match[i+1] != "" {
+			n, _ := strconv.Atoi(match[i+1])
+			seconds += n * unit
+		}
+	}
+	return seconds, true
+}
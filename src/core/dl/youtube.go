@@ -10,11 +10,9 @@ package dl
 
 import (
         "context"
-        "crypto/rand"
         "errors"
         "fmt"
         "log"
-        "math/big"
         "os"
         "os/exec"
         "path/filepath"
@@ -101,18 +99,18 @@ func (y *YouTubeData) IsValid() bool {
 // It returns a PlatformTracks object or an error if the information cannot be fetched.
 func (y *YouTubeData) GetInfo(_ context.Context) (cache.PlatformTracks, error) {
         if !y.IsValid() {
-                return cache.PlatformTracks{}, errors.New("the provided URL is invalid or the platform is not supported")
+                return cache.PlatformTracks{}, NewError(ErrNotFound, errors.New("the provided URL is invalid or the platform is not supported"))
         }
 
         y.Query = y.normalizeYouTubeURL(y.Query)
         videoID := y.extractVideoID(y.Query)
         if videoID == "" {
-                return cache.PlatformTracks{}, errors.New("unable to extract the video ID")
+                return cache.PlatformTracks{}, NewError(ErrNotFound, errors.New("unable to extract the video ID"))
         }
 
         tracks, err := searchYouTube(y.Query)
         if err != nil {
-                return cache.PlatformTracks{}, err
+                return cache.PlatformTracks{}, classifyError(err, ErrBackendDown)
         }
 
         for _, track := range tracks {
@@ -121,7 +119,7 @@ func (y *YouTubeData) GetInfo(_ context.Context) (cache.PlatformTracks, error) {
                 }
         }
 
-        return cache.PlatformTracks{}, errors.New("no video results were found")
+        return cache.PlatformTracks{}, NewError(ErrNotFound, errors.New("no video results were found"))
 }
 
 // Search performs a search for a track on YouTube.
@@ -129,10 +127,10 @@ func (y *YouTubeData) GetInfo(_ context.Context) (cache.PlatformTracks, error) {
 func (y *YouTubeData) Search(_ context.Context) (cache.PlatformTracks, error) {
         tracks, err := searchYouTube(y.Query)
         if err != nil {
-                return cache.PlatformTracks{}, err
+                return cache.PlatformTracks{}, classifyError(err, ErrBackendDown)
         }
         if len(tracks) == 0 {
-                return cache.PlatformTracks{}, errors.New("no video results were found")
+                return cache.PlatformTracks{}, NewError(ErrNotFound, errors.New("no video results were found"))
         }
         return cache.PlatformTracks{Results: tracks}, nil
 }
@@ -141,38 +139,68 @@ func (y *YouTubeData) Search(_ context.Context) (cache.PlatformTracks, error) {
 // It returns a TrackInfo object or an error if the track cannot be found.
 func (y *YouTubeData) GetTrack(ctx context.Context) (cache.TrackInfo, error) {
         if y.Query == "" {
-                return cache.TrackInfo{}, errors.New("the query is empty")
+                return cache.TrackInfo{}, NewError(ErrNotFound, errors.New("the query is empty"))
         }
         if !y.IsValid() {
-                return cache.TrackInfo{}, errors.New("the provided URL is invalid or the platform is not supported")
+                return cache.TrackInfo{}, NewError(ErrNotFound, errors.New("the provided URL is invalid or the platform is not supported"))
+        }
+
+        if !IsApiConfigured() {
+                return y.trackFromSearch(ctx)
         }
 
-        // Always use the new API for YouTube tracks
         api := NewApiData(y.Query)
         return api.GetTrack(ctx)
 }
 
+// trackFromSearch builds a TrackInfo from the YouTube search metadata when no API gateway is configured.
+// The returned track has no CDN URL, so downloads go straight to yt-dlp.
+func (y *YouTubeData) trackFromSearch(ctx context.Context) (cache.TrackInfo, error) {
+        info, err := y.GetInfo(ctx)
+        if err != nil {
+                return cache.TrackInfo{}, err
+        }
+
+        track := info.Results[0]
+        return cache.TrackInfo{
+                URL:      track.URL,
+                Name:     track.Name,
+                TC:       track.ID,
+                Cover:    track.Cover,
+                Duration: track.Duration,
+                Channel:  track.Channel,
+                Views:    track.Views,
+                Platform: cache.YouTube,
+        }, nil
+}
+
 // downloadTrack handles the download of a track from YouTube.
 // It returns the file path of the downloaded track or an error if the download fails.
-func (y *YouTubeData) downloadTrack(ctx context.Context, info cache.TrackInfo, video bool) (string, error) {
-        // Try custom YouTube API first if configured
-        if IsCustomAPIConfigured() {
-                filePath, apiErr := y.DownloadWithCustomAPI(ctx, info.TC, video)
+func (y *YouTubeData) downloadTrack(ctx context.Context, info cache.TrackInfo, video bool, profile config.QualityProfile) (string, error) {
+        // Try the API gateway first if configured
+        if IsApiConfigured() {
+                filePath, apiErr := y.downloadWithApi(ctx, info, video, profile)
                 if apiErr == nil {
                         return filePath, nil
                 }
-                log.Printf("Custom API download failed, falling back to yt-dlp: %v", apiErr)
+                log.Printf("API download failed, falling back to yt-dlp: %v", apiErr)
         }
 
         // Fallback to yt-dlp
-        filePath, err := y.downloadWithYtDlp(ctx, info.TC, video)
+        filePath, err := y.downloadWithYtDlp(ctx, info.TC, video, profile)
         return filePath, err
 }
 
 // BuildYtdlpParams constructs the command-line parameters for yt-dlp to download media.
-// It takes a video ID and a boolean indicating whether to download video or audio, and returns the corresponding parameters.
-func (y *YouTubeData) BuildYtdlpParams(videoID string, video bool) []string {
-        outputTemplate := filepath.Join(config.Conf.DownloadsDir, "%(id)s.%(ext)s")
+// It takes a video ID, a boolean indicating whether to download video or audio, the quality profile
+// and an optional cookies file, and returns the corresponding parameters.
+func (y *YouTubeData) BuildYtdlpParams(videoID string, video bool, profile config.QualityProfile, cookieFile string) []string {
+        // Non-standard profiles get their own file name so they never reuse another profile's download.
+        fileName := "%(id)s.%(ext)s"
+        if profile.Name != config.QualityStandard {
+                fileName = "%(id)s_" + profile.Name + ".%(ext)s"
+        }
+        outputTemplate := filepath.Join(config.Conf.DownloadsDir, fileName)
 
         params := []string{
                 "yt-dlp",
@@ -195,14 +223,14 @@ func (y *YouTubeData) BuildYtdlpParams(videoID string, video bool) []string {
                 "-o", outputTemplate,
         }
 
-        formatSelector := "bestaudio[ext=m4a]/bestaudio[ext=mp4]/bestaudio[ext=webm]/bestaudio/best"
+        formatSelector := profile.AudioFormat
         if video {
-                formatSelector = "bestvideo[ext=mp4][height<=1080]+bestaudio[ext=m4a]/best[ext=mp4][height<=1080]"
+                formatSelector = profile.VideoFormat
                 params = append(params, "--merge-output-format", "mp4")
         }
         params = append(params, "-f", formatSelector)
 
-        if cookieFile := y.getCookieFile(); cookieFile != "" {
+        if cookieFile != "" {
                 params = append(params, "--cookies", cookieFile)
         } else if config.Conf.Proxy != "" {
                 params = append(params, "--proxy", config.Conf.Proxy)
@@ -216,8 +244,9 @@ func (y *YouTubeData) BuildYtdlpParams(videoID string, video bool) []string {
 
 // downloadWithYtDlp downloads media from YouTube using the yt-dlp command-line tool.
 // It returns the file path of the downloaded track or an error if the download fails.
-func (y *YouTubeData) downloadWithYtDlp(ctx context.Context, videoID string, video bool) (string, error) {
-        ytdlpParams := y.BuildYtdlpParams(videoID, video)
+func (y *YouTubeData) downloadWithYtDlp(ctx context.Context, videoID string, video bool, profile config.QualityProfile) (string, error) {
+        cookieFile := Cookies.Pick()
+        ytdlpParams := y.BuildYtdlpParams(videoID, video, profile, cookieFile)
         cmd := exec.CommandContext(ctx, ytdlpParams[0], ytdlpParams[1:]...)
 
         output, err := cmd.Output()
@@ -225,16 +254,18 @@ func (y *YouTubeData) downloadWithYtDlp(ctx context.Context, videoID string, vid
                 var exitErr *exec.ExitError
                 if errors.As(err, &exitErr) {
                         stderr := string(exitErr.Stderr)
-                        return "", fmt.Errorf("yt-dlp failed with exit code %d: %s", exitErr.ExitCode(), stderr)
+                        Cookies.ReportFailure(cookieFile, stderr)
+                        return "", classifyError(fmt.Errorf("yt-dlp failed with exit code %d: %s", exitErr.ExitCode(), stderr), ErrUnknown)
                 }
 
                 if errors.Is(ctx.Err(), context.DeadlineExceeded) {
-                        return "", fmt.Errorf("yt-dlp timed out for video ID: %s", videoID)
+                        return "", NewError(ErrBackendDown, fmt.Errorf("yt-dlp timed out for video ID: %s", videoID))
                 }
 
                 return "", fmt.Errorf("an unexpected error occurred while downloading %s: %w", videoID, err)
         }
 
+        Cookies.ReportSuccess(cookieFile)
         downloadedPathStr := strings.TrimSpace(string(output))
         if downloadedPathStr == "" {
                 return "", fmt.Errorf("no output path was returned for %s", videoID)
@@ -247,37 +278,31 @@ func (y *YouTubeData) downloadWithYtDlp(ctx context.Context, videoID string, vid
         return downloadedPathStr, nil
 }
 
-// getCookieFile retrieves the path to a cookie file from the configured list.
-// It returns the path to a randomly selected cookie file.
-func (y *YouTubeData) getCookieFile() string {
-        cookiesPath := config.Conf.CookiesPath
-        if len(cookiesPath) == 0 {
-                return ""
-        }
-        n, err := rand.Int(rand.Reader, big.NewInt(int64(len(cookiesPath))))
-        if err != nil {
-                log.Printf("Could not generate a random number: %v", err)
-                return cookiesPath[0]
-        }
-
-        return cookiesPath[n.Int64()]
-}
-
-// downloadWithApi downloads a track using the external API.
+// downloadWithApi downloads a track using the API gateway.
+// Audio reuses the CDN URL already resolved by GetTrack, in the one format the gateway serves,
+// so the profile only picks the video height; video asks the gateway for a video URL.
 // It returns the file path of the downloaded track or an error if the download fails.
-func (y *YouTubeData) downloadWithApi(ctx context.Context, videoID string, _ bool) (string, error) {
-        videoUrl := fmt.Sprintf("https://www.youtube.com/watch?v=%s", videoID)
-        api := NewApiData(videoUrl)
-        track, err := api.GetTrack(ctx)
-        if err != nil {
-                return "", err
+func (y *YouTubeData) downloadWithApi(ctx context.Context, info cache.TrackInfo, video bool, profile config.QualityProfile) (string, error) {
+        if info.TC == "" {
+                return "", NewError(ErrNotFound, errors.New("the track has no video ID"))
         }
 
-        down, err := NewDownload(ctx, track)
-        if err != nil {
-                log.Println("Error creating download: " + err.Error())
-                return "", err
+        downloadURL := info.CdnURL
+        if video || downloadURL == "" {
+                api := NewApiData(fmt.Sprintf("https://www.youtube.com/watch?v=%s", info.TC))
+                var err error
+                if video {
+                        downloadURL, err = api.GetVideoURL(ctx, profile.Height)
+                } else {
+                        var track cache.TrackInfo
+                        track, err = api.GetTrack(ctx)
+                        downloadURL = track.CdnURL
+                }
+                if err != nil {
+                        return "", err
+                }
         }
 
-        return down.Process()
+        log.Printf("Downloading %s from the API gateway.", info.TC)
+        return DownloadFile(ctx, downloadURL, "", false)
 }
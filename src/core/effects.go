@@ -0,0 +1,65 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package core
+
+import "strings"
+
+// EffectPreset is a named ffmpeg audio filter chain that can be applied to a chat's stream.
+type EffectPreset struct {
+	Name   string  // Name is the identifier used in commands and callbacks.
+	Label  string  // Label is the text shown on buttons and in the now-playing message.
+	Filter string  // Filter is the ffmpeg audio filter chain.
+	Tempo  float64 // Tempo is how much faster the effect plays the track, 1 if it keeps the original speed.
+}
+
+// EffectPresets lists the available audio effects in the order they are shown.
+var EffectPresets = []EffectPreset{
+	{
+		Name:   "bassboost",
+		Label:  "🔊 Bass Boost",
+		Filter: "equalizer=f=60:width_type=o:width=2:g=10,equalizer=f=150:width_type=o:width=2:g=4,alimiter=limit=0.95",
+		Tempo:  1,
+	},
+	{
+		Name:   "nightcore",
+		Label:  "🌙 Nightcore",
+		Filter: "aresample=48000,asetrate=48000*1.25,aresample=48000,atempo=0.92",
+		Tempo:  1.15,
+	},
+	{
+		Name:   "8d",
+		Label:  "🎧 8D",
+		Filter: "apulsator=hz=0.125",
+		Tempo:  1,
+	},
+	{
+		Name:   "echo",
+		Label:  "🏔 Echo",
+		Filter: "aecho=0.8:0.88:60:0.4",
+		Tempo:  1,
+	},
+	{
+		Name:   "karaoke",
+		Label:  "🎤 Karaoke",
+		Filter: "aformat=channel_layouts=stereo,pan=stereo|c0=c0-c1|c1=c1-c0",
+		Tempo:  1,
+	},
+}
+
+// GetEffectPreset looks up an effect by name.
+// It returns the preset and true if found, otherwise an empty preset and false.
+func GetEffectPreset(name string) (EffectPreset, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, e := range EffectPresets {
+		if e.Name == name {
+			return e, true
+		}
+	}
+	return EffectPreset{}, false
+}
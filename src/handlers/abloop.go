@@ -0,0 +1,68 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc"
+
+	"github.com/amarnathcjd/gogram/telegram"
+)
+
+// abLoopHandler handles the /abloop command.
+// "/abloop 1:00 1:30" repeats that section of the current track and "/abloop off" stops repeating it.
+func abLoopHandler(m *telegram.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+	if !cache.ChatCache.IsActive(chatID) || cache.ChatCache.GetPlayingTrack(chatID) == nil {
+		_, err := m.Reply(lang.GetString(langCode, "no_track_playing"))
+		return err
+	}
+
+	args := strings.Fields(m.Args())
+	if len(args) == 1 && (strings.EqualFold(args[0], "off") || strings.EqualFold(args[0], "clear")) {
+		if err := vc.Calls.ClearRepeat(chatID); err != nil {
+			_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "abloop_error"), err.Error()))
+			return nil
+		}
+		_, err := m.Reply(lang.GetString(langCode, "abloop_cleared"))
+		return err
+	}
+
+	if len(args) != 2 {
+		_, err := m.Reply(lang.GetString(langCode, "abloop_usage"))
+		return err
+	}
+
+	start, err := cache.MinToSec(args[0])
+	if err != nil {
+		_, err := m.Reply(lang.GetString(langCode, "abloop_invalid"))
+		return err
+	}
+	end, err := cache.MinToSec(args[1])
+	if err != nil {
+		_, err := m.Reply(lang.GetString(langCode, "abloop_invalid"))
+		return err
+	}
+
+	if err := vc.Calls.SetRepeat(chatID, start, end); err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "abloop_error"), err.Error()))
+		return nil
+	}
+
+	_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "abloop_set"), cache.SecToMin(start), cache.SecToMin(end)))
+	return err
+}
@@ -0,0 +1,140 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"ashokshau/tgmusic/src/vc"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+const assistantsUsage = "Usage:\n<code>/assistants</code> — list assistants and their load\n<code>/assistants add [pyrogram|telethon|gogram] SESSION</code> — start a new assistant\n<code>/assistants drain NAME</code> — take no new chats and move its chats away\n<code>/assistants undrain NAME</code> — put a drained assistant back into rotation\n<code>/assistants restart NAME</code> — restart a disconnected assistant\n<code>/assistants rm NAME</code> — drain and stop an assistant"
+
+// assistantsHandler handles the /assistants command for managing the assistant pool at runtime.
+func assistantsHandler(m *tg.NewMessage) error {
+	args := strings.Fields(m.Args())
+	action := ""
+	if len(args) > 0 {
+		action = strings.ToLower(args[0])
+	}
+	if action == "" || action == "list" {
+		_, _ = m.Reply(formatAssistantList())
+		return tg.ErrEndGroup
+	}
+
+	if action == "add" {
+		addAssistant(m, args[1:])
+		return tg.ErrEndGroup
+	}
+	if len(args) < 2 {
+		_, _ = m.Reply(assistantsUsage)
+		return tg.ErrEndGroup
+	}
+
+	name := args[1]
+	var done string
+	var err error
+	switch action {
+	case "drain":
+		done = "🚰 <code>%s</code> is drained; its chats have moved to other assistants."
+		err = vc.Calls.DrainAssistant(name)
+	case "undrain":
+		done = "♻️ <code>%s</code> is back in rotation."
+		err = vc.Calls.UndrainAssistant(name)
+	case "restart":
+		_, _ = m.Reply(fmt.Sprintf("🔄 Restarting <code>%s</code>…", html.EscapeString(name)))
+		done = "✅ <code>%s</code> has been restarted."
+		err = vc.Calls.RestartAssistant(name)
+	case "rm", "remove", "del":
+		done = "🗑 <code>%s</code> has been stopped and removed."
+		err = vc.Calls.RemoveAssistant(name)
+	default:
+		_, _ = m.Reply(assistantsUsage)
+		return tg.ErrEndGroup
+	}
+
+	if err != nil {
+		_, _ = m.Reply("❗ " + html.EscapeString(err.Error()))
+		return tg.ErrEndGroup
+	}
+	_, _ = m.Reply(fmt.Sprintf(done, html.EscapeString(name)))
+	return tg.ErrEndGroup
+}
+
+// addAssistant starts an assistant from the session string in args, which may start with its session type.
+// The command message is deleted, since the session string grants full access to the account.
+func addAssistant(m *tg.NewMessage, args []string) {
+	_, _ = m.Delete()
+
+	sessionType := ""
+	if len(args) > 1 {
+		switch t := strings.ToLower(args[0]); t {
+		case "pyrogram", "telethon", "gogram":
+			sessionType, args = t, args[1:]
+		}
+	}
+	if len(args) != 1 {
+		_, _ = m.Respond(assistantsUsage)
+		return
+	}
+
+	name, err := vc.Calls.AddAssistant(sessionType, args[0])
+	if err != nil {
+		_, _ = m.Respond("❗ Failed to start the assistant: " + html.EscapeString(err.Error()))
+		return
+	}
+	_, _ = m.Respond(fmt.Sprintf("✅ <code>%s</code> has started. Add its session to a <code>STRING</code> variable to keep it after a restart.", name))
+}
+
+// formatAssistantList renders every assistant with its account, load and health.
+func formatAssistantList() string {
+	assistants := vc.Calls.ListAssistants()
+	if len(assistants) == 0 {
+		return "No assistants are running.\n\n" + assistantsUsage
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("<b>🤖 Assistants (%d)</b>\n\n", len(assistants)))
+	for _, a := range assistants {
+		status := "🟢"
+		switch {
+		case !a.Connected:
+			status = "🔴"
+		case a.Hold != "" || a.Drained:
+			status = "🟡"
+		}
+
+		username := "—"
+		if a.Username != "" {
+			username = "@" + a.Username
+		}
+		chats := "?"
+		if a.Chats >= 0 {
+			chats = fmt.Sprint(a.Chats)
+		}
+
+		b.WriteString(fmt.Sprintf("%s <code>%s</code> — %s (<code>%d</code>)\n", status, a.Name, html.EscapeString(username), a.ID))
+		b.WriteString(fmt.Sprintf("   Calls: %d • Chats: %s", a.Calls, chats))
+		if a.Drained {
+			b.WriteString(" • drained")
+		}
+		if !a.Connected {
+			b.WriteString(" • disconnected")
+		}
+		if a.Hold != "" {
+			b.WriteString(" • " + html.EscapeString(a.Hold))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -55,7 +55,7 @@ func playCallbackHandler(cb *telegram.CallbackQuery) error {
 			currentTrack.URL, currentTrack.Name,
 			cache.SecToMin(currentTrack.Duration),
 			currentTrack.User,
-		)
+		) + vc.EffectLine(langCode, chatID)
 	}
 
 	switch {
@@ -123,6 +123,37 @@ func playCallbackHandler(cb *telegram.CallbackQuery) error {
 		text := buildTrackMessage(lang.GetString(langCode, "now_playing"), "🎵") + fmt.Sprintf(lang.GetString(langCode, "unmuted_by"), cb.Sender.FirstName)
 		_, _ = cb.Edit(text, &telegram.SendOptions{ReplyMarkup: core.ControlButtons("unmute")})
 		return nil
+	case strings.Contains(data, "play_volup"), strings.Contains(data, "play_voldown"):
+		step := vc.VolumeStep
+		if strings.Contains(data, "play_voldown") {
+			step = -step
+		}
+		volume, err := vc.Calls.SetVolume(chatID, db.Instance.GetVolume(ctx, chatID)+step)
+		if err != nil {
+			_, _ = cb.Answer(fmt.Sprintf(lang.GetString(langCode, "volume_error"), err.Error()), &telegram.CallbackOptions{Alert: true})
+			return nil
+		}
+		_, _ = cb.Answer(fmt.Sprintf(lang.GetString(langCode, "volume_changed"), volume))
+		return nil
+
+	case strings.Contains(data, "play_seekback"), strings.Contains(data, "play_seekfwd"), strings.Contains(data, "play_replay"):
+		var position int
+		var err error
+		switch {
+		case strings.Contains(data, "play_replay"):
+			position, err = vc.Calls.SeekTo(chatID, 0)
+		case strings.Contains(data, "play_seekback"):
+			position, err = vc.Calls.SeekBy(chatID, -vc.SeekStep)
+		default:
+			position, err = vc.Calls.SeekBy(chatID, vc.SeekStep)
+		}
+		if err != nil {
+			_, _ = cb.Answer(fmt.Sprintf(lang.GetString(langCode, "seek_error"), err.Error()), &telegram.CallbackOptions{Alert: true})
+			return nil
+		}
+		_, _ = cb.Answer(fmt.Sprintf(lang.GetString(langCode, "seek_position"), cache.SecToMin(position)))
+		return nil
+
 	case strings.Contains(data, "play_add_to_list"):
 		userID := cb.GetSenderID()
 		playlists, err := db.Instance.GetUserPlaylists(ctx, userID)
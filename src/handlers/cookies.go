@@ -0,0 +1,144 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"ashokshau/tgmusic/src/core/dl"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+const cookiesUsage = "Usage:\n<code>/cookies</code> — list cookies and their health\n<code>/cookies add</code> — reply to a cookies.txt document\n<code>/cookies rm N</code> — remove cookie #N\n<code>/cookies reset N</code> — put cookie #N back into rotation"
+
+// cookiesHandler handles the /cookies command for managing the yt-dlp cookie pool at runtime.
+func cookiesHandler(m *tg.NewMessage) error {
+	args := strings.Fields(m.Args())
+	if len(args) > 0 {
+		args[0] = strings.ToLower(args[0])
+	}
+	if len(args) == 0 || args[0] == "list" {
+		_, _ = m.Reply(formatCookieList())
+		return tg.ErrEndGroup
+	}
+
+	switch args[0] {
+	case "add":
+		addCookie(m)
+	case "rm", "remove", "del":
+		if len(args) < 2 {
+			_, _ = m.Reply(cookiesUsage)
+			return tg.ErrEndGroup
+		}
+		index, err := strconv.Atoi(args[1])
+		if err != nil {
+			_, _ = m.Reply("❗ The cookie number must be an integer.")
+			return tg.ErrEndGroup
+		}
+		path, err := dl.Cookies.Remove(index)
+		if err != nil {
+			_, _ = m.Reply("❗ " + err.Error())
+			return tg.ErrEndGroup
+		}
+		_, _ = m.Reply(fmt.Sprintf("🗑 Removed <code>%s</code>.", filepath.Base(path)))
+	case "reset":
+		if len(args) < 2 {
+			_, _ = m.Reply(cookiesUsage)
+			return tg.ErrEndGroup
+		}
+		index, err := strconv.Atoi(args[1])
+		if err != nil {
+			_, _ = m.Reply("❗ The cookie number must be an integer.")
+			return tg.ErrEndGroup
+		}
+		path, err := dl.Cookies.Reset(index)
+		if err != nil {
+			_, _ = m.Reply("❗ " + err.Error())
+			return tg.ErrEndGroup
+		}
+		_, _ = m.Reply(fmt.Sprintf("♻️ <code>%s</code> is back in rotation.", filepath.Base(path)))
+	default:
+		_, _ = m.Reply(cookiesUsage)
+	}
+
+	return tg.ErrEndGroup
+}
+
+// addCookie downloads the replied cookies.txt document and adds it to the pool.
+func addCookie(m *tg.NewMessage) {
+	reply, err := m.GetReplyMessage()
+	if err != nil || reply == nil || reply.Document() == nil {
+		_, _ = m.Reply("❗ Reply to a cookies.txt document with <code>/cookies add</code>.")
+		return
+	}
+
+	if reply.File != nil && reply.File.Size > 1024*1024 {
+		_, _ = m.Reply("❗ The cookies file is too large.")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	tmpPath := filepath.Join(os.TempDir(), fmt.Sprintf("cookies_%d_%d.txt", m.SenderID(), time.Now().UnixNano()))
+	filePath, err := reply.Download(&tg.DownloadOptions{FileName: tmpPath, Ctx: ctx})
+	if err != nil {
+		_, _ = m.Reply(fmt.Sprintf("❗ Failed to download the file: %s", err.Error()))
+		return
+	}
+	defer func() {
+		_ = os.Remove(filePath)
+	}()
+
+	path, err := dl.Cookies.Add(filePath)
+	if err != nil {
+		_, _ = m.Reply("❗ " + err.Error())
+		return
+	}
+
+	_, _ = m.Reply(fmt.Sprintf("✅ Added <code>%s</code> to the cookie pool (%d total).", filepath.Base(path), dl.Cookies.Count()))
+}
+
+// formatCookieList renders the health of every cookie in the pool.
+func formatCookieList() string {
+	cookies := dl.Cookies.List()
+	if len(cookies) == 0 {
+		return "🍪 No cookies are configured.\n\n" + cookiesUsage
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("<b>🍪 Cookie pool (%d)</b>\n\n", len(cookies)))
+	for i, c := range cookies {
+		state := "🟢 healthy"
+		if c.Retired {
+			state = "🔴 retired"
+		} else if c.ConsecutiveFailures > 0 {
+			state = "🟡 failing"
+		}
+
+		lastUsed := "never"
+		if !c.LastUsed.IsZero() {
+			lastUsed = time.Since(c.LastUsed).Truncate(time.Second).String() + " ago"
+		}
+
+		sb.WriteString(fmt.Sprintf("%d. <code>%s</code> — %s\n   ✔ %d  ✖ %d  • used %s\n",
+			i+1, filepath.Base(c.Path), state, c.Successes, c.Failures, lastUsed))
+		if c.LastError != "" {
+			sb.WriteString(fmt.Sprintf("   ↳ <i>%s</i>\n", html.EscapeString(truncate(c.LastError, 120))))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,56 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// crossfadeHandler handles the /crossfade command.
+func crossfadeHandler(m *tg.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	args := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(m.Args()), "s"))
+	if args == "" {
+		_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "crossfade_usage"), vc.MaxCrossfade, db.Instance.GetCrossfade(ctx, chatID)))
+		return err
+	}
+
+	seconds, err := strconv.Atoi(args)
+	if args == "off" {
+		seconds, err = 0, nil
+	}
+	if err != nil || seconds < 0 || seconds > vc.MaxCrossfade {
+		_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "crossfade_invalid"), vc.MaxCrossfade))
+		return err
+	}
+
+	if _, err := vc.Calls.SetCrossfade(chatID, seconds); err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "crossfade_error"), err.Error()))
+		return nil
+	}
+
+	if seconds == 0 {
+		_, err = m.Reply(lang.GetString(langCode, "crossfade_disabled"))
+		return err
+	}
+	_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "crossfade_enabled"), seconds))
+	return err
+}
@@ -143,3 +143,71 @@ func loggerHandler(m *telegram.NewMessage) error {
 
 	return telegram.ErrEndGroup
 }
+
+// privateCallsHandler handles the /privatecalls command, which sets how the assistants handle private calls.
+func privateCallsHandler(m *telegram.NewMessage) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+
+	botID := m.Client.Me().ID
+	args := strings.ToLower(strings.TrimSpace(m.Args()))
+	if args == "" {
+		current := db.Instance.GetPrivateCalls(ctx, botID)
+		if current == "" {
+			current = config.Conf.PrivateCalls + " (from PRIVATE_CALLS)"
+		}
+		_, _ = m.Reply(fmt.Sprintf("Usage: /privatecalls [off|queue|answer]\nCurrent mode: %s", current))
+		return telegram.ErrEndGroup
+	}
+
+	switch args {
+	case config.PrivateCallsOff, config.PrivateCallsQueue, config.PrivateCallsAnswer:
+	default:
+		_, _ = m.Reply("Invalid argument. Use 'off', 'queue' or 'answer'.")
+		return telegram.ErrEndGroup
+	}
+
+	if err := db.Instance.SetPrivateCalls(ctx, botID, args); err != nil {
+		_, _ = m.Reply(fmt.Sprintf("Failed to save the private call mode: %s", err.Error()))
+		return telegram.ErrEndGroup
+	}
+
+	_, _ = m.Reply(fmt.Sprintf("Private calls set to %s.", args))
+	return telegram.ErrEndGroup
+}
+
+// qualityHandler handles the /quality command, which sets the global default quality profile.
+// Chats that picked a profile in /settings keep their own choice.
+func qualityHandler(m *telegram.NewMessage) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+
+	botID := m.Client.Me().ID
+	args := strings.ToLower(strings.TrimSpace(m.Args()))
+	if args == "" {
+		current := db.Instance.GetDefaultQuality(ctx, botID)
+		if current == "" {
+			current = config.Conf.DefaultQuality + " (from DEFAULT_QUALITY)"
+		}
+		names := make([]string, 0, len(config.QualityProfiles))
+		for _, p := range config.QualityProfiles {
+			names = append(names, p.Name)
+		}
+		_, _ = m.Reply(fmt.Sprintf("Usage: /quality [%s]\nCurrent default: %s", strings.Join(names, "|"), current))
+		return telegram.ErrEndGroup
+	}
+
+	profile, ok := config.GetQualityProfile(args)
+	if !ok {
+		_, _ = m.Reply("Unknown quality profile. Send /quality to see the available ones.")
+		return telegram.ErrEndGroup
+	}
+
+	if err := db.Instance.SetDefaultQuality(ctx, botID, profile.Name); err != nil {
+		_, _ = m.Reply(fmt.Sprintf("Failed to save the default quality: %s", err.Error()))
+		return telegram.ErrEndGroup
+	}
+
+	_, _ = m.Reply(fmt.Sprintf("Default quality set to %s.", profile.Name))
+	return telegram.ErrEndGroup
+}
@@ -0,0 +1,95 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"ashokshau/tgmusic/src/core"
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// effectHandler handles the /effect command.
+func effectHandler(m *tg.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+	if !cache.ChatCache.IsActive(chatID) || cache.ChatCache.GetPlayingTrack(chatID) == nil {
+		_, err := m.Reply(lang.GetString(langCode, "no_track_playing"))
+		return err
+	}
+
+	name := strings.ToLower(strings.TrimSpace(m.Args()))
+	if name == "" {
+		current := cache.ChatCache.GetPlayback(chatID).Effect
+		_, err := m.Reply(effectMenuText(langCode, current), &tg.SendOptions{ReplyMarkup: core.EffectsKeyboard(current)})
+		return err
+	}
+
+	if _, ok := core.GetEffectPreset(name); !ok && name != "off" {
+		_, err := m.Reply(lang.GetString(langCode, "effect_invalid"))
+		return err
+	}
+
+	if err := vc.Calls.ApplyEffect(chatID, name); err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "effect_error"), err.Error()))
+		return nil
+	}
+
+	_, err := m.Reply(effectResultText(langCode, name))
+	return err
+}
+
+// effectCallbackHandler handles the buttons of the /effect menu.
+func effectCallbackHandler(cb *tg.CallbackQuery) error {
+	chatID := cb.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+	if !cache.ChatCache.IsActive(chatID) || cache.ChatCache.GetPlayingTrack(chatID) == nil {
+		_, _ = cb.Answer(lang.GetString(langCode, "no_track_playing"), &tg.CallbackOptions{Alert: true})
+		return nil
+	}
+
+	name := strings.TrimPrefix(cb.DataString(), "effect_")
+	if err := vc.Calls.ApplyEffect(chatID, name); err != nil {
+		_, _ = cb.Answer(fmt.Sprintf(lang.GetString(langCode, "effect_error"), err.Error()), &tg.CallbackOptions{Alert: true})
+		return nil
+	}
+
+	current := cache.ChatCache.GetPlayback(chatID).Effect
+	_, _ = cb.Answer(lang.GetString(langCode, "settings_updated"))
+	_, _ = cb.Edit(effectMenuText(langCode, current), &tg.SendOptions{ReplyMarkup: core.EffectsKeyboard(current)})
+	return nil
+}
+
+// effectMenuText renders the header of the /effect menu with the active effect.
+func effectMenuText(langCode, current string) string {
+	label := lang.GetString(langCode, "effect_none")
+	if effect, ok := core.GetEffectPreset(current); ok {
+		label = effect.Label
+	}
+	return fmt.Sprintf(lang.GetString(langCode, "effect_choose"), label)
+}
+
+// effectResultText renders the confirmation shown after an effect was applied or turned off.
+func effectResultText(langCode, name string) string {
+	effect, ok := core.GetEffectPreset(name)
+	if !ok {
+		return lang.GetString(langCode, "effect_cleared")
+	}
+	return fmt.Sprintf(lang.GetString(langCode, "effect_applied"), effect.Label)
+}
@@ -15,6 +15,7 @@ import (
 	"ashokshau/tgmusic/src/core/cache"
 	"ashokshau/tgmusic/src/core/db"
 	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc"
 
 	"github.com/amarnathcjd/gogram/telegram"
 )
@@ -99,9 +100,18 @@ func adminModeCB(cb *telegram.CallbackQuery) bool {
 	ctx, cancel := db.Ctx()
 	defer cancel()
 	langCode := db.Instance.GetLang(ctx, chatID)
+	opts := &telegram.CallbackOptions{Alert: true}
+
+	// In the bot's DM the buttons control the sender's own private call, as privateCallMode allows for commands.
+	if chatID > 0 {
+		if chatID == cb.SenderID && vc.HasPrivateCall(cb.SenderID) {
+			return true
+		}
+		_, _ = cb.Answer(lang.GetString(langCode, "private_call_none"), opts)
+		return false
+	}
 
 	botStatus, err := cache.GetUserAdmin(cb.Client, chatID, cb.Client.Me().ID, false)
-	opts := &telegram.CallbackOptions{Alert: true}
 
 	if err != nil {
 		if strings.Contains(err.Error(), "is not an admin in chat") {
@@ -212,3 +222,24 @@ func playMode(m *telegram.NewMessage) bool {
 
 	return true
 }
+
+// privateCallMode lets a user in a private call with an assistant control its queue from the bot's DM.
+// It returns false for group chats, which playMode and adminMode handle.
+func privateCallMode(m *telegram.NewMessage) bool {
+	if !m.IsPrivate() {
+		return false
+	}
+	if vc.HasPrivateCall(m.SenderID()) {
+		return true
+	}
+
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, m.ChannelID())
+	if vc.Calls.PrivateCallMode() == config.PrivateCallsOff {
+		_, _ = m.Reply(lang.GetString(langCode, "private_calls_disabled"))
+	} else {
+		_, _ = m.Reply(lang.GetString(langCode, "private_call_none"))
+	}
+	return false
+}
@@ -9,6 +9,11 @@
 package handlers
 
 import (
+	"fmt"
+	"strings"
+
+	"ashokshau/tgmusic/src/core/cache"
+
 	"github.com/amarnathcjd/gogram/telegram"
 )
 
@@ -79,3 +84,75 @@ func truncate(s string, max int) string {
 	}
 	return s[:max]
 }
+
+// trimRange holds the start and end positions, in seconds, requested for a track. An end of 0 means the end of the track.
+type trimRange struct {
+	start, end int
+}
+
+// parseTrimArgs removes the --from and --to options from a command's arguments.
+// It returns the remaining arguments and the requested range. Both "--from 1:30" and "--from=1:30" are accepted.
+func parseTrimArgs(args string) (string, trimRange, error) {
+	var trim trimRange
+	var rest []string
+	fields := strings.Fields(args)
+	for i := 0; i < len(fields); i++ {
+		name, value, hasValue := strings.Cut(fields[i], "=")
+		if name != "--from" && name != "--to" {
+			rest = append(rest, fields[i])
+			continue
+		}
+
+		if !hasValue {
+			if i+1 >= len(fields) {
+				return "", trim, fmt.Errorf("missing value for %s", name)
+			}
+			i++
+			value = fields[i]
+		}
+
+		seconds, err := cache.MinToSec(value)
+		if err != nil {
+			return "", trim, err
+		}
+		if name == "--from" {
+			trim.start = seconds
+		} else {
+			trim.end = seconds
+		}
+	}
+	return strings.Join(rest, " "), trim, nil
+}
+
+// cutFlag removes every occurrence of a flag without a value, such as --screen, from a command's arguments.
+// It returns the remaining arguments and whether the flag was given.
+func cutFlag(args, flag string) (string, bool) {
+	var rest []string
+	found := false
+	for _, field := range strings.Fields(args) {
+		if field == flag {
+			found = true
+			continue
+		}
+		rest = append(rest, field)
+	}
+	return strings.Join(rest, " "), found
+}
+
+// apply stores the range on a track. An end past the track's duration plays the track to its end.
+// It returns false if the range is empty or starts after the track ends.
+func (t trimRange) apply(track *cache.CachedTrack) bool {
+	if t.end > 0 && t.end <= t.start {
+		return false
+	}
+	if track.Duration > 0 {
+		if t.start >= track.Duration {
+			return false
+		}
+		if t.end >= track.Duration {
+			t.end = 0
+		}
+	}
+	track.StartAt, track.EndAt = t.start, t.end
+	return true
+}
@@ -0,0 +1,63 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// idleHandler handles the /idle command.
+func idleHandler(m *tg.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	args := strings.Fields(strings.ToLower(m.Args()))
+	if len(args) == 0 {
+		policy, minutes := db.Instance.GetIdlePolicy(ctx, chatID), db.Instance.GetIdleTimeout(ctx, chatID)
+		_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "idle_usage"), policy, minutes))
+		return err
+	}
+
+	policy := args[0]
+	minutes := db.Instance.GetIdleTimeout(ctx, chatID)
+	var err error
+	if len(args) > 1 {
+		minutes, err = strconv.Atoi(strings.TrimSuffix(args[1], "m"))
+	}
+	if !vc.IsIdlePolicy(policy) || len(args) > 2 || err != nil || minutes < vc.MinIdleTimeout || minutes > vc.MaxIdleTimeout {
+		_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "idle_invalid"), vc.MinIdleTimeout, vc.MaxIdleTimeout))
+		return err
+	}
+
+	if err := db.Instance.SetIdlePolicy(ctx, chatID, policy); err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "idle_error"), err.Error()))
+		return nil
+	}
+	if policy == vc.IdleOff {
+		_, err = m.Reply(lang.GetString(langCode, "idle_disabled"))
+		return err
+	}
+
+	if err := db.Instance.SetIdleTimeout(ctx, chatID, minutes); err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "idle_error"), err.Error()))
+		return nil
+	}
+	_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "idle_set_"+policy), minutes))
+	return err
+}
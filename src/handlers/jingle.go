@@ -0,0 +1,110 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// jingleHandler handles the /jingle command, which configures the clip played at the start of each track.
+func jingleHandler(m *tg.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	args := strings.Fields(m.Args())
+	if len(args) == 0 {
+		_, err := m.Reply(jingleStatusText(langCode, chatID))
+		return err
+	}
+
+	var err error
+	var reply string
+	switch strings.ToLower(args[0]) {
+	case "set":
+		source := ""
+		if len(args) > 1 {
+			source = args[1]
+		} else if m.IsReply() {
+			if rMsg, rErr := m.GetReplyMessage(); rErr == nil && isValidMedia(rMsg) {
+				source = rMsg.Link()
+			}
+		}
+		if !vc.IsJingleSource(source) {
+			_, err := m.Reply(lang.GetString(langCode, "jingle_invalid"))
+			return err
+		}
+		err = db.Instance.SetJingle(ctx, chatID, source)
+		reply = lang.GetString(langCode, "jingle_set")
+
+	case "off":
+		err = db.Instance.SetJingle(ctx, chatID, "")
+		reply = lang.GetString(langCode, "jingle_removed")
+
+	case "mode":
+		if len(args) < 2 || (args[1] != "overlay" && args[1] != "intro") {
+			_, err := m.Reply(lang.GetString(langCode, "jingle_usage"))
+			return err
+		}
+		err = db.Instance.SetJingleIntro(ctx, chatID, args[1] == "intro")
+		reply = fmt.Sprintf(lang.GetString(langCode, "jingle_mode_set"), args[1])
+
+	case "announce":
+		if len(args) < 2 || (args[1] != "on" && args[1] != "off") {
+			_, err := m.Reply(lang.GetString(langCode, "jingle_usage"))
+			return err
+		}
+		if args[1] == "on" && config.Conf.TtsCommand == "" {
+			_, err := m.Reply(lang.GetString(langCode, "jingle_tts_unavailable"))
+			return err
+		}
+		err = db.Instance.SetAnnounce(ctx, chatID, args[1] == "on")
+		reply = fmt.Sprintf(lang.GetString(langCode, "jingle_announce_set"), args[1])
+
+	default:
+		_, err := m.Reply(lang.GetString(langCode, "jingle_usage"))
+		return err
+	}
+
+	if err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "jingle_error"), err.Error()))
+		return nil
+	}
+	_, err = m.Reply(reply)
+	return err
+}
+
+// jingleStatusText renders the chat's jingle settings followed by the command's usage.
+func jingleStatusText(langCode string, chatID int64) string {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+
+	source := db.Instance.GetJingle(ctx, chatID)
+	if source == "" {
+		source = lang.GetString(langCode, "jingle_none")
+	}
+	mode := "overlay"
+	if db.Instance.GetJingleIntro(ctx, chatID) {
+		mode = "intro"
+	}
+	announce := "off"
+	if db.Instance.GetAnnounce(ctx, chatID) {
+		announce = "on"
+	}
+	return fmt.Sprintf(lang.GetString(langCode, "jingle_status"), source, mode, announce) + "\n\n" + lang.GetString(langCode, "jingle_usage")
+}
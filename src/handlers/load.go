@@ -34,6 +34,8 @@ func LoadModules(c *tg.Client) {
 	c.On("command:play", playHandler, tg.Custom(playMode))
 	c.On("command:vPlay", vPlayHandler, tg.Custom(playMode))
 	c.On("command:stream", streamHandler, tg.Custom(playMode))
+	c.On("command:play", playHandler, tg.Custom(privateCallMode))
+	c.On("command:skip", skipHandler, tg.Custom(privateCallMode))
 
 	c.On("command:stopStream", stopStreamHandler, tg.Custom(adminMode))
 	c.On("command:loop", loopHandler, tg.Custom(adminMode))
@@ -47,7 +49,19 @@ func LoadModules(c *tg.Client) {
 	c.On("command:resume", resumeHandler, tg.Custom(adminMode))
 	c.On("command:queue", queueHandler, tg.Custom(adminMode))
 	c.On("command:seek", seekHandler, tg.Custom(adminMode))
+	c.On("command:replay", replayHandler, tg.Custom(adminMode))
+	c.On("command:abloop", abLoopHandler, tg.Custom(adminMode))
 	c.On("command:speed", speedHandler, tg.Custom(adminMode))
+	c.On("command:effect", effectHandler, tg.Custom(adminMode))
+	c.On("command:volume", volumeHandler, tg.Custom(adminMode))
+	c.On("command:normalize", normalizeHandler, tg.Custom(adminMode))
+	c.On("command:crossfade", crossfadeHandler, tg.Custom(adminMode))
+	c.On("command:jingle", jingleHandler, tg.Custom(adminMode))
+	c.On("command:sleep", sleepHandler, tg.Custom(adminMode))
+	c.On("command:idle", idleHandler, tg.Custom(adminMode))
+	c.On("command:screen", screenHandler, tg.Custom(adminMode))
+	c.On("command:record", recordHandler, tg.Custom(adminMode))
+	c.On("command:vol", volumeHandler, tg.Custom(adminMode))
 	c.On("command:authList", authListHandler, tg.Custom(adminMode))
 	c.On("command:addAuth", addAuthHandler, tg.Custom(adminMode))
 	c.On("command:auth", addAuthHandler, tg.Custom(adminMode))
@@ -63,9 +77,13 @@ func LoadModules(c *tg.Client) {
 	c.On("command:clearAss", clearAssistantsHandler, tg.Custom(isDev))
 	c.On("command:leaveAll", leaveAllHandler, tg.Custom(isDev))
 	c.On("command:logger", loggerHandler, tg.Custom(isDev))
+	c.On("command:quality", qualityHandler, tg.Custom(isDev))
+	c.On("command:privatecalls", privateCallsHandler, tg.Custom(isDev))
 	c.On("command:broadcast", broadcastHandler, tg.Custom(isDev))
 	c.On("command:gCast", broadcastHandler, tg.Custom(isDev))
 	c.On("command:cancelBroadcast", cancelBroadcastHandler, tg.Custom(isDev))
+	c.On("command:cookies", cookiesHandler, tg.Custom(isDev))
+	c.On("command:assistants", assistantsHandler, tg.Custom(isDev))
 
 	c.On("command:settings", settingsHandler, tg.Custom(adminMode))
 
@@ -83,6 +101,7 @@ func LoadModules(c *tg.Client) {
 	c.On("command:myplaylists", myPlaylistsHandler)
 
 	c.On("callback:play_\\w+", playCallbackHandler, tg.CustomCallback(adminModeCB))
+	c.On("callback:effect_\\w+", effectCallbackHandler, tg.CustomCallback(adminModeCB))
 	c.On("callback:vcplay_\\w+", vcPlayHandler)
 	c.On("callback:help_\\w+", helpCallbackHandler)
 	c.On("callback:settings_\\w+", settingsCallbackHandler)
@@ -12,7 +12,6 @@ import (
 	"fmt"
 
 	"ashokshau/tgmusic/src/core"
-	"ashokshau/tgmusic/src/core/cache"
 	"ashokshau/tgmusic/src/core/db"
 	"ashokshau/tgmusic/src/lang"
 	"ashokshau/tgmusic/src/vc"
@@ -30,10 +29,15 @@ func muteHandler(m *telegram.NewMessage) error {
 	ctx, cancel := db.Ctx()
 	defer cancel()
 	langCode := db.Instance.GetLang(ctx, chatID)
-	if !cache.ChatCache.IsActive(chatID) {
+	state, muted := vc.Calls.GetPlayerState(chatID)
+	if state != vc.PlayerPlaying && state != vc.PlayerPaused {
 		_, err := m.Reply(lang.GetString(langCode, "no_track_playing"))
 		return err
 	}
+	if muted {
+		_, err := m.Reply(lang.GetString(langCode, "player_already_muted"))
+		return err
+	}
 
 	if _, err := vc.Calls.Mute(chatID); err != nil {
 		_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "mute_error"), err.Error()))
@@ -54,10 +58,15 @@ func unmuteHandler(m *telegram.NewMessage) error {
 	ctx, cancel := db.Ctx()
 	defer cancel()
 	langCode := db.Instance.GetLang(ctx, chatID)
-	if !cache.ChatCache.IsActive(chatID) {
+	state, muted := vc.Calls.GetPlayerState(chatID)
+	if state != vc.PlayerPlaying && state != vc.PlayerPaused {
 		_, err := m.Reply(lang.GetString(langCode, "no_track_playing"))
 		return err
 	}
+	if !muted {
+		_, err := m.Reply(lang.GetString(langCode, "player_not_muted"))
+		return err
+	}
 
 	if _, err := vc.Calls.Unmute(chatID); err != nil {
 		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "unmute_error"), err.Error()))
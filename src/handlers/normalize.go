@@ -0,0 +1,67 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// normalizeHandler handles the /normalize command.
+// It takes "on", "off" or a target loudness in LUFS such as -14.
+func normalizeHandler(m *tg.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	args := strings.ToLower(strings.TrimSpace(m.Args()))
+	if args == "" {
+		status := lang.GetString(langCode, "normalize_off")
+		if target := db.Instance.GetLoudnessTarget(ctx, chatID); target != 0 {
+			status = fmt.Sprintf("%d LUFS", target)
+		}
+		_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "normalize_usage"), status))
+		return err
+	}
+
+	var target int
+	switch args {
+	case "off":
+		target = 0
+	case "on":
+		target = vc.DefaultLoudnessTarget
+	default:
+		value, err := strconv.Atoi(strings.TrimSpace(strings.TrimSuffix(args, "lufs")))
+		if err != nil || value < vc.MinLoudnessTarget || value > vc.MaxLoudnessTarget {
+			_, err := m.Reply(lang.GetString(langCode, "normalize_invalid"))
+			return err
+		}
+		target = value
+	}
+
+	if err := vc.Calls.SetLoudnessTarget(chatID, target); err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "normalize_error"), err.Error()))
+		return nil
+	}
+
+	if target == 0 {
+		_, err := m.Reply(lang.GetString(langCode, "normalize_disabled"))
+		return err
+	}
+	_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "normalize_enabled"), target))
+	return err
+}
@@ -12,7 +12,6 @@ import (
 	"fmt"
 
 	"ashokshau/tgmusic/src/core"
-	"ashokshau/tgmusic/src/core/cache"
 	"ashokshau/tgmusic/src/core/db"
 	"ashokshau/tgmusic/src/lang"
 	"ashokshau/tgmusic/src/vc"
@@ -26,7 +25,12 @@ func pauseHandler(m *telegram.NewMessage) error {
 	ctx, cancel := db.Ctx()
 	defer cancel()
 	langCode := db.Instance.GetLang(ctx, chatID)
-	if !cache.ChatCache.IsActive(chatID) {
+	switch state, _ := vc.Calls.GetPlayerState(chatID); state {
+	case vc.PlayerPlaying:
+	case vc.PlayerPaused:
+		_, _ = m.Reply(lang.GetString(langCode, "player_already_paused"))
+		return nil
+	default:
 		_, _ = m.Reply(lang.GetString(langCode, "no_track_playing"))
 		return nil
 	}
@@ -51,7 +55,12 @@ func resumeHandler(m *telegram.NewMessage) error {
 		return nil
 	}
 
-	if !cache.ChatCache.IsActive(chatID) {
+	switch state, _ := vc.Calls.GetPlayerState(chatID); state {
+	case vc.PlayerPaused:
+	case vc.PlayerPlaying:
+		_, _ = m.Reply(lang.GetString(langCode, "player_not_paused"))
+		return nil
+	default:
 		_, _ = m.Reply(lang.GetString(langCode, "no_track_playing"))
 		return nil
 	}
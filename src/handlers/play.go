@@ -54,9 +54,18 @@ func handlePlay(m *telegram.NewMessage, isVideo bool) error {
 
 	isReply := m.IsReply()
 	url := getUrl(m, isReply)
-	args := m.Args()
+	args, trim, err := parseTrimArgs(m.Args())
+	if err != nil {
+		_, _ = m.Reply(lang.GetString(langCode, "play_trim_invalid"))
+		return telegram.ErrEndGroup
+	}
+	// Audio tracks have no video to share, so /play ignores --screen.
+	args, screen := cutFlag(args, "--screen")
+	screen = screen && isVideo
+	if trim.start == 0 && url != "" {
+		trim.start = dl.URLTimestamp(url)
+	}
 	rMsg := m
-	var err error
 
 	input := coalesce(url, args)
 	if strings.HasPrefix(input, "tgpl_") {
@@ -82,7 +91,7 @@ func handlePlay(m *telegram.NewMessage, isVideo bool) error {
 			logger.Warn("failed to send message: %v", err)
 			return telegram.ErrEndGroup
 		}
-		return handleMultipleTracks(m, updater, tracks, chatID, isVideo, langCode)
+		return handleMultipleTracks(m, updater, tracks, chatID, isVideo, screen, langCode)
 	}
 
 	if username, msgID, ok := parseTelegramURL(input); ok {
@@ -115,7 +124,7 @@ func handlePlay(m *telegram.NewMessage, isVideo bool) error {
 	}
 
 	if isReply && isValidMedia(rMsg) {
-		return handleMedia(m, updater, rMsg, chatID, isVideo, langCode)
+		return handleMedia(m, updater, rMsg, chatID, isVideo, screen, trim, langCode)
 	}
 
 	wrapper := dl.NewDownloaderWrapper(input)
@@ -129,7 +138,7 @@ func handlePlay(m *telegram.NewMessage, isVideo bool) error {
 		defer cancel()
 		trackInfo, err := wrapper.GetInfo(ctx)
 		if err != nil {
-			_, _ = updater.Edit(fmt.Sprintf(lang.GetString(langCode, "play_fetch_error"), err.Error()))
+			_, _ = updater.Edit(vc.DownloadErrorText(langCode, err, isDev(m)))
 			return telegram.ErrEndGroup
 		}
 
@@ -137,16 +146,16 @@ func handlePlay(m *telegram.NewMessage, isVideo bool) error {
 			_, _ = updater.Edit(lang.GetString(langCode, "play_no_tracks_found"))
 			return telegram.ErrEndGroup
 		}
-		return handleUrl(m, updater, trackInfo, chatID, isVideo, langCode)
+		return handleUrl(m, updater, trackInfo, chatID, isVideo, screen, trim, langCode)
 	}
 
 	ctx2, cancel2 := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel2()
-	return handleTextSearch(m, updater, wrapper, chatID, isVideo, ctx2, langCode)
+	return handleTextSearch(m, updater, wrapper, chatID, isVideo, screen, trim, ctx2, langCode)
 }
 
 // handleMedia handles playing media from a message.
-func handleMedia(m *telegram.NewMessage, updater *telegram.NewMessage, dlMsg *telegram.NewMessage, chatId int64, isVideo bool, langCode string) error {
+func handleMedia(m *telegram.NewMessage, updater *telegram.NewMessage, dlMsg *telegram.NewMessage, chatId int64, isVideo, screen bool, trim trimRange, langCode string) error {
 	if dlMsg.File.Size > config.Conf.MaxFileSize {
 		_, err := updater.Edit(fmt.Sprintf(lang.GetString(langCode, "play_file_too_large"), config.Conf.MaxFileSize/(1024*1024)))
 		if err != nil {
@@ -166,7 +175,11 @@ func handleMedia(m *telegram.NewMessage, updater *telegram.NewMessage, dlMsg *te
 	if cache.ChatCache.IsActive(chatId) {
 		saveCache := cache.CachedTrack{
 			URL: dlMsg.Link(), Name: fileName, User: m.Sender.FirstName, TrackID: fileId,
-			Duration: dur, IsVideo: isVideo, Platform: cache.Telegram,
+			Duration: dur, IsVideo: isVideo, Screen: screen, Platform: cache.Telegram,
+		}
+		if !trim.apply(&saveCache) {
+			_, err := updater.Edit(lang.GetString(langCode, "play_trim_invalid"))
+			return err
 		}
 		queue := cache.ChatCache.GetQueue(chatId)
 		cache.ChatCache.AddSong(chatId, &saveCache)
@@ -197,14 +210,14 @@ func handleMedia(m *telegram.NewMessage, updater *telegram.NewMessage, dlMsg *te
 		Name: fileName, Duration: dur, URL: dlMsg.Link(), ID: fileId, Channel: "Telegram", Views: "69K", Platform: cache.Telegram,
 	}
 
-	return handleSingleTrack(m, updater, track, filePath, chatId, isVideo, langCode)
+	return handleSingleTrack(m, updater, track, filePath, chatId, isVideo, screen, trim, langCode)
 }
 
 // handleTextSearch handles a text search for a song.
-func handleTextSearch(m *telegram.NewMessage, updater *telegram.NewMessage, wrapper *dl.DownloaderWrapper, chatId int64, isVideo bool, ctx context.Context, langCode string) error {
+func handleTextSearch(m *telegram.NewMessage, updater *telegram.NewMessage, wrapper *dl.DownloaderWrapper, chatId int64, isVideo, screen bool, trim trimRange, ctx context.Context, langCode string) error {
 	searchResult, err := wrapper.Search(ctx)
 	if err != nil {
-		_, err = updater.Edit(fmt.Sprintf(lang.GetString(langCode, "play_search_failed"), err.Error()))
+		_, err = updater.Edit(vc.DownloadErrorText(langCode, err, isDev(m)))
 		return err
 	}
 
@@ -219,24 +232,24 @@ func handleTextSearch(m *telegram.NewMessage, updater *telegram.NewMessage, wrap
 		return err
 	}
 
-	return handleSingleTrack(m, updater, song, "", chatId, isVideo, langCode)
+	return handleSingleTrack(m, updater, song, "", chatId, isVideo, screen, trim, langCode)
 }
 
 // handleUrl handles a URL search for a song.
-func handleUrl(m *telegram.NewMessage, updater *telegram.NewMessage, trackInfo cache.PlatformTracks, chatId int64, isVideo bool, langCode string) error {
+func handleUrl(m *telegram.NewMessage, updater *telegram.NewMessage, trackInfo cache.PlatformTracks, chatId int64, isVideo, screen bool, trim trimRange, langCode string) error {
 	if len(trackInfo.Results) == 1 {
 		track := trackInfo.Results[0]
 		if _track := cache.ChatCache.GetTrackIfExists(chatId, track.ID); _track != nil {
 			_, err := updater.Edit(lang.GetString(langCode, "play_track_already_in_queue"))
 			return err
 		}
-		return handleSingleTrack(m, updater, track, "", chatId, isVideo, langCode)
+		return handleSingleTrack(m, updater, track, "", chatId, isVideo, screen, trim, langCode)
 	}
-	return handleMultipleTracks(m, updater, trackInfo.Results, chatId, isVideo, langCode)
+	return handleMultipleTracks(m, updater, trackInfo.Results, chatId, isVideo, screen, langCode)
 }
 
 // handleSingleTrack handles a single track.
-func handleSingleTrack(m *telegram.NewMessage, updater *telegram.NewMessage, song cache.MusicTrack, filePath string, chatId int64, isVideo bool, langCode string) error {
+func handleSingleTrack(m *telegram.NewMessage, updater *telegram.NewMessage, song cache.MusicTrack, filePath string, chatId int64, isVideo, screen bool, trim trimRange, langCode string) error {
 	if song.Duration > int(config.Conf.SongDurationLimit) {
 		_, err := updater.Edit(fmt.Sprintf(lang.GetString(langCode, "play_song_too_long"), config.Conf.SongDurationLimit/60))
 		return err
@@ -245,7 +258,11 @@ func handleSingleTrack(m *telegram.NewMessage, updater *telegram.NewMessage, son
 	saveCache := cache.CachedTrack{
 		URL: song.URL, Name: song.Name, User: m.Sender.FirstName, FilePath: filePath,
 		Thumbnail: song.Cover, TrackID: song.ID, Duration: song.Duration, Channel: song.Channel, Views: song.Views,
-		IsVideo: isVideo, Platform: song.Platform,
+		IsVideo: isVideo, Screen: screen, Platform: song.Platform,
+	}
+	if !trim.apply(&saveCache) {
+		_, err := updater.Edit(lang.GetString(langCode, "play_trim_invalid"))
+		return err
 	}
 
 	if cache.ChatCache.IsActive(chatId) {
@@ -269,9 +286,9 @@ func handleSingleTrack(m *telegram.NewMessage, updater *telegram.NewMessage, son
 
 		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
 		defer cancel()
-		dlResult, trackInfo, err := vc.DownloadSong(ctx, &saveCache, m.Client)
+		dlResult, trackInfo, err := vc.DownloadSong(ctx, chatId, &saveCache, m.Client)
 		if err != nil {
-			_, err = updater.Edit(fmt.Sprintf(lang.GetString(langCode, "play_song_download_failed"), err.Error()))
+			_, err = updater.Edit(vc.DownloadErrorText(langCode, err, isDev(m)))
 			return err
 		}
 
@@ -286,7 +303,7 @@ func handleSingleTrack(m *telegram.NewMessage, updater *telegram.NewMessage, son
 	cache.ChatCache.SetActive(chatId, true)
 	cache.ChatCache.AddSong(chatId, &saveCache)
 
-	if err := vc.Calls.PlayMedia(chatId, saveCache.FilePath, saveCache.IsVideo, ""); err != nil {
+	if err := vc.Calls.StartTrack(chatId, &saveCache); err != nil {
 		_, err = updater.Edit(err.Error())
 		return err
 	}
@@ -294,7 +311,7 @@ func handleSingleTrack(m *telegram.NewMessage, updater *telegram.NewMessage, son
 	nowPlaying := fmt.Sprintf(
 		lang.GetString(langCode, "play_now_playing"),
 		saveCache.URL, saveCache.Name, cache.SecToMin(song.Duration), saveCache.User,
-	)
+	) + vc.EffectLine(langCode, chatId)
 
 	thumb, _ := core.GenThumb(saveCache)
 	_, err := updater.Edit(nowPlaying, &telegram.SendOptions{
@@ -311,7 +328,7 @@ func handleSingleTrack(m *telegram.NewMessage, updater *telegram.NewMessage, son
 }
 
 // handleMultipleTracks handles multiple tracks.
-func handleMultipleTracks(m *telegram.NewMessage, updater *telegram.NewMessage, tracks []cache.MusicTrack, chatId int64, isVideo bool, langCode string) error {
+func handleMultipleTracks(m *telegram.NewMessage, updater *telegram.NewMessage, tracks []cache.MusicTrack, chatId int64, isVideo, screen bool, langCode string) error {
 	isActive := cache.ChatCache.IsActive(chatId)
 	queue := cache.ChatCache.GetQueue(chatId)
 
@@ -328,7 +345,7 @@ func handleMultipleTracks(m *telegram.NewMessage, updater *telegram.NewMessage,
 		saveCache := cache.CachedTrack{
 			Name: track.Name, TrackID: track.ID, Duration: track.Duration,
 			Thumbnail: track.Cover, User: m.Sender.FirstName, Platform: track.Platform,
-			IsVideo: isVideo, URL: track.URL, Channel: track.Channel, Views: track.Views,
+			IsVideo: isVideo, Screen: screen, URL: track.URL, Channel: track.Channel, Views: track.Views,
 		}
 		if !isActive && i == 0 {
 			saveCache.Loop = 1
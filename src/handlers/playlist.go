@@ -16,6 +16,7 @@ import (
 	"ashokshau/tgmusic/src/core/db"
 	"ashokshau/tgmusic/src/core/dl"
 	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc"
 
 	"github.com/amarnathcjd/gogram/telegram"
 )
@@ -119,7 +120,7 @@ func addToPlaylistHandler(m *telegram.NewMessage) error {
 	}
 	trackInfo, err := wrapper.GetInfo(ctx)
 	if err != nil {
-		_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "play_fetch_error"), err.Error()))
+		_, err := m.Reply(vc.DownloadErrorText(langCode, err, isDev(m)))
 		return err
 	}
 
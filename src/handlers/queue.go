@@ -31,7 +31,7 @@ func queueHandler(m *tg.NewMessage) error {
 	chat := m.Channel
 	queue := cache.ChatCache.GetQueue(chatID)
 	if len(queue) == 0 {
-		_, _ = m.Reply(lang.GetString(langCode, "queue_empty"))
+		_, _ = m.Reply(lang.GetString(langCode, "queue_empty") + vc.RecordLine(langCode, chatID))
 		return nil
 	}
 
@@ -56,6 +56,12 @@ func queueHandler(m *tg.NewMessage) error {
 	} else {
 		b.WriteString(lang.GetString(langCode, "queue_loop_off"))
 	}
+	state, muted := vc.Calls.GetPlayerState(chatID)
+	stateText := lang.GetString(langCode, "player_state_"+state.String())
+	if muted {
+		stateText += lang.GetString(langCode, "player_state_muted")
+	}
+	b.WriteString(fmt.Sprintf(lang.GetString(langCode, "queue_state"), stateText))
 	b.WriteString(lang.GetString(langCode, "queue_progress"))
 	if playedTime > 0 && playedTime < math.MaxInt {
 		b.WriteString(cache.SecToMin(int(playedTime)))
@@ -63,6 +69,8 @@ func queueHandler(m *tg.NewMessage) error {
 		b.WriteString("0:00")
 	}
 	b.WriteString(" min\n")
+	b.WriteString(vc.SleepLine(langCode, chatID))
+	b.WriteString(vc.RecordLine(langCode, chatID))
 
 	if len(queue) > 1 {
 		b.WriteString(fmt.Sprintf(lang.GetString(langCode, "queue_next_up"), len(queue)-1))
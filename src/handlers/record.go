@@ -0,0 +1,54 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// recordHandler handles the /record command, which records the voice chat and uploads the recording when it stops.
+func recordHandler(m *tg.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+	if chatID > 0 {
+		_, err := m.Reply(lang.GetString(langCode, "supergroup_command_only"))
+		return err
+	}
+
+	switch strings.ToLower(strings.TrimSpace(m.Args())) {
+	case "start":
+		if err := vc.Calls.StartRecording(chatID); err != nil {
+			_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "record_error"), err.Error()))
+			return err
+		}
+		_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "record_started"), config.Conf.RecordMaxDuration))
+		return err
+
+	case "stop":
+		if err := vc.Calls.StopRecording(chatID); err != nil {
+			_, err = m.Reply(err.Error())
+			return err
+		}
+		return nil
+
+	default:
+		_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "record_usage"), config.Conf.RecordMaxDuration))
+		return err
+	}
+}
@@ -0,0 +1,49 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// screenHandler handles the /screen command, which sends video tracks as a screen share instead of a camera feed.
+func screenHandler(m *tg.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+	if chatID > 0 {
+		_, err := m.Reply(lang.GetString(langCode, "supergroup_command_only"))
+		return err
+	}
+
+	args := strings.Fields(strings.ToLower(m.Args()))
+	if len(args) != 1 || (args[0] != "on" && args[0] != "off") {
+		status := "off"
+		if db.Instance.GetScreenShare(ctx, chatID) {
+			status = "on"
+		}
+		_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "screen_usage"), status))
+		return err
+	}
+
+	if err := vc.Calls.SetScreenShare(chatID, args[0] == "on"); err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "screen_error"), err.Error()))
+		return nil
+	}
+	_, err := m.Reply(lang.GetString(langCode, "screen_"+args[0]))
+	return err
+}
@@ -10,7 +10,7 @@ package handlers
 
 import (
 	"fmt"
-	"strconv"
+	"strings"
 
 	"ashokshau/tgmusic/src/core/cache"
 	"ashokshau/tgmusic/src/core/db"
@@ -21,56 +21,73 @@ import (
 )
 
 // seekHandler handles the /seek command.
+// It accepts an absolute position such as 83 or 1:23, or a relative jump such as +30 or -1:00.
 func seekHandler(m *telegram.NewMessage) error {
 	chatID := m.ChannelID()
 	ctx, cancel := db.Ctx()
 	defer cancel()
 	langCode := db.Instance.GetLang(ctx, chatID)
-	if !cache.ChatCache.IsActive(chatID) {
+	if !cache.ChatCache.IsActive(chatID) || cache.ChatCache.GetPlayingTrack(chatID) == nil {
 		_, err := m.Reply(lang.GetString(langCode, "no_track_playing"))
 		return err
 	}
 
-	playingSong := cache.ChatCache.GetPlayingTrack(chatID)
-	if playingSong == nil {
-		_, err := m.Reply(lang.GetString(langCode, "no_track_playing"))
-		return err
-	}
-
-	args := m.Args()
+	args := strings.TrimSpace(m.Args())
 	if args == "" {
 		_, _ = m.Reply(lang.GetString(langCode, "seek_usage"))
 		return nil
 	}
 
-	seekTime, err := strconv.Atoi(args)
-	if err != nil {
-		_, _ = m.Reply(lang.GetString(langCode, "seek_invalid_time"))
-		return nil
+	sign := 0
+	switch args[0] {
+	case '+':
+		sign = 1
+	case '-':
+		sign = -1
 	}
 
-	if seekTime < 0 || seekTime < 20 {
-		_, _ = m.Reply(lang.GetString(langCode, "seek_min_time"))
+	value := args
+	if sign != 0 {
+		value = strings.TrimPrefix(args, args[:1])
+	}
+	// Only the one sign read above is allowed; MinToSec would take another as part of the number.
+	seconds, err := cache.MinToSec(value)
+	if err != nil || strings.HasPrefix(value, "+") || strings.HasPrefix(value, "-") {
+		_, _ = m.Reply(lang.GetString(langCode, "seek_invalid_time"))
 		return nil
 	}
 
-	currDur, err := vc.Calls.PlayedTime(chatID)
+	var position int
+	if sign == 0 {
+		position, err = vc.Calls.SeekTo(chatID, seconds)
+	} else {
+		position, err = vc.Calls.SeekBy(chatID, sign*seconds)
+	}
 	if err != nil {
-		_, _ = m.Reply(lang.GetString(langCode, "seek_fetch_duration_error"))
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "seek_error"), err.Error()))
 		return nil
 	}
 
-	toSeek := int(currDur) + seekTime
-	if toSeek >= playingSong.Duration {
-		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "seek_beyond_duration"), cache.SecToMin(playingSong.Duration)))
-		return nil
+	_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "seek_success"), cache.SecToMin(position)))
+	return nil
+}
+
+// replayHandler handles the /replay command.
+func replayHandler(m *telegram.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+	if !cache.ChatCache.IsActive(chatID) || cache.ChatCache.GetPlayingTrack(chatID) == nil {
+		_, err := m.Reply(lang.GetString(langCode, "no_track_playing"))
+		return err
 	}
 
-	if err = vc.Calls.SeekStream(chatID, playingSong.FilePath, toSeek, playingSong.Duration, playingSong.IsVideo); err != nil {
+	if _, err := vc.Calls.SeekTo(chatID, 0); err != nil {
 		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "seek_error"), err.Error()))
 		return nil
 	}
 
-	_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "seek_success"), cache.SecToMin(toSeek)))
-	return nil
+	_, err := m.Reply(lang.GetString(langCode, "replay_success"))
+	return err
 }
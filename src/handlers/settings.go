@@ -12,10 +12,12 @@ import (
 	"fmt"
 	"strings"
 
+	"ashokshau/tgmusic/src/config"
 	"ashokshau/tgmusic/src/core"
 	"ashokshau/tgmusic/src/core/cache"
 	"ashokshau/tgmusic/src/core/db"
 	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc"
 
 	"github.com/amarnathcjd/gogram/telegram"
 )
@@ -54,7 +56,7 @@ func settingsHandler(m *telegram.NewMessage) error {
 		m.Chat.Title, getPlayMode, getAdminMode)
 
 	_, err = m.Reply(text, &telegram.SendOptions{
-		ReplyMarkup: core.SettingsKeyboard(getPlayMode, getAdminMode),
+		ReplyMarkup: core.SettingsKeyboard(getPlayMode, getAdminMode, vc.GetQualityProfile(chatID).Name),
 	})
 	return err
 }
@@ -101,12 +103,20 @@ func settingsCallbackHandler(c *telegram.CallbackQuery) error {
 		cache.Everyone: true,
 	}
 
+	// Quality values are profile names rather than permission levels
+	if settingType == "quality" {
+		_, ok := config.GetQualityProfile(settingValue)
+		validValues = map[string]bool{settingValue: ok}
+	}
+
 	if !validValues[settingValue] {
 		_, _ = c.Answer(lang.GetString(langCode, "settings_update_invalid"), &telegram.CallbackOptions{Alert: true})
 		return nil
 	}
 
 	switch settingType {
+	case "quality":
+		_ = db.Instance.SetQuality(ctx, chatID, settingValue)
 	case "play":
 		_ = db.Instance.SetPlayMode(ctx, chatID, settingValue)
 	case "admin":
@@ -129,7 +139,7 @@ func settingsCallbackHandler(c *telegram.CallbackQuery) error {
 		chat.Title, getPlayMode, getAdminMode)
 
 	_, err = c.Edit(text, &telegram.SendOptions{
-		ReplyMarkup: core.SettingsKeyboard(getPlayMode, getAdminMode),
+		ReplyMarkup: core.SettingsKeyboard(getPlayMode, getAdminMode, vc.GetQualityProfile(chatID).Name),
 	})
 	if err != nil {
 		logger.Warn("Failed to edit message: %v", err)
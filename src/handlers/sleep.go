@@ -0,0 +1,81 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// sleepHandler handles the /sleep command.
+func sleepHandler(m *tg.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	args := strings.ToLower(strings.TrimSpace(m.Args()))
+	switch args {
+	case "":
+		status := vc.SleepLine(langCode, chatID)
+		if status == "" {
+			status = lang.GetString(langCode, "sleep_none") + "\n"
+		}
+		_, err := m.Reply(status + "\n" + lang.GetString(langCode, "sleep_usage"))
+		return err
+
+	case "cancel", "off":
+		key := "sleep_cancelled"
+		if !vc.Calls.CancelSleep(chatID) {
+			key = "sleep_none"
+		}
+		_, err := m.Reply(lang.GetString(langCode, key))
+		return err
+
+	case "end":
+		if err := vc.Calls.SetStopAfterCurrent(chatID); err != nil {
+			_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "sleep_error"), err.Error()))
+			return nil
+		}
+		_, err := m.Reply(lang.GetString(langCode, "sleep_after_track"))
+		return err
+	}
+
+	d, ok := parseSleepDuration(args)
+	if !ok || d < vc.MinSleep || d > vc.MaxSleep {
+		_, err := m.Reply(lang.GetString(langCode, "sleep_invalid"))
+		return err
+	}
+
+	if err := vc.Calls.SetSleepTimer(chatID, d); err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "sleep_error"), err.Error()))
+		return nil
+	}
+
+	_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "sleep_set"), cache.SecToMin(int(d.Seconds()))))
+	return err
+}
+
+// parseSleepDuration parses a duration such as 30m or 1h30m, reading a bare number as minutes.
+func parseSleepDuration(s string) (time.Duration, bool) {
+	if minutes, err := strconv.Atoi(s); err == nil {
+		return time.Duration(minutes) * time.Minute, true
+	}
+	d, err := time.ParseDuration(s)
+	return d, err == nil
+}
@@ -0,0 +1,57 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// volumeHandler handles the /volume command.
+func volumeHandler(m *tg.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	args := strings.TrimSuffix(strings.TrimSpace(m.Args()), "%")
+	if args == "" {
+		_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "volume_usage"), db.Instance.GetVolume(ctx, chatID)))
+		return err
+	}
+
+	volume, err := strconv.Atoi(args)
+	if err != nil || volume < 0 || volume > vc.MaxVolume {
+		_, err := m.Reply(lang.GetString(langCode, "volume_invalid"))
+		return err
+	}
+
+	if !cache.ChatCache.IsActive(chatID) {
+		_ = db.Instance.SetVolume(ctx, chatID, volume)
+		_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "volume_set"), volume))
+		return err
+	}
+
+	volume, err = vc.Calls.SetVolume(chatID, volume)
+	if err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "volume_error"), err.Error()))
+		return nil
+	}
+
+	_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "volume_set"), volume))
+	return err
+}
@@ -0,0 +1,272 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package vc
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+)
+
+// AssistantInfo describes one assistant of the pool for the /assistants command.
+type AssistantInfo struct {
+	Name      string
+	ID        int64
+	Username  string
+	Calls     int    // Calls is the number of active calls.
+	Chats     int    // Chats is the number of chats assigned to the assistant, -1 if they could not be counted.
+	Connected bool   // Connected reports whether the client is connected to Telegram.
+	Drained   bool   // Drained reports whether the assistant takes no new chats.
+	Hold      string // Hold describes why and for how long the assistant is held, empty if it is not.
+}
+
+// drainedAssistants holds the assistants that take no new chats and whose chats move to other assistants.
+var drainedAssistants = struct {
+	sync.Mutex
+	byName map[string]bool
+}{byName: make(map[string]bool)}
+
+// restartingAssistants holds the assistants whose client is being restarted.
+// Their chats keep them, but no new chat is given to them until the restart ends.
+var restartingAssistants = struct {
+	sync.Mutex
+	byName map[string]bool
+}{byName: make(map[string]bool)}
+
+// isRestarting reports whether an assistant's client is being restarted.
+func isRestarting(name string) bool {
+	restartingAssistants.Lock()
+	defer restartingAssistants.Unlock()
+	return restartingAssistants.byName[name]
+}
+
+// setRestarting marks an assistant as restarting or done restarting.
+func setRestarting(name string, restarting bool) {
+	restartingAssistants.Lock()
+	defer restartingAssistants.Unlock()
+
+	if restarting {
+		restartingAssistants.byName[name] = true
+	} else {
+		delete(restartingAssistants.byName, name)
+	}
+}
+
+// isDrained reports whether an assistant is drained.
+func isDrained(name string) bool {
+	drainedAssistants.Lock()
+	defer drainedAssistants.Unlock()
+	return drainedAssistants.byName[name]
+}
+
+// setDrained drains an assistant or puts it back into rotation.
+func setDrained(name string, drained bool) {
+	drainedAssistants.Lock()
+	defer drainedAssistants.Unlock()
+
+	if drained {
+		drainedAssistants.byName[name] = true
+	} else {
+		delete(drainedAssistants.byName, name)
+	}
+}
+
+// ListAssistants describes every assistant of the pool, in the order they were started.
+func (c *TelegramCalls) ListAssistants() []AssistantInfo {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	counts, err := db.Instance.CountAssistantChats(ctx)
+	if err != nil {
+		logger.Warn("[ListAssistants] Failed to count the chats of each assistant: %v", err)
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	infos := make([]AssistantInfo, 0, len(c.availableClients))
+	for _, name := range c.availableClients {
+		call, ok := c.uBContext[name]
+		if !ok {
+			continue
+		}
+
+		info := AssistantInfo{Name: name, Chats: -1, Drained: isDrained(name), Calls: len(call.Calls())}
+		if me := call.App.Me(); me != nil {
+			info.ID, info.Username = me.ID, me.Username
+		}
+		if client, ok := c.clients[name]; ok {
+			info.Connected = client.IsConnected()
+		}
+		if counts != nil {
+			info.Chats = counts[name]
+		}
+		if hold, ok := getAssistantHold(name); ok {
+			info.Hold = fmt.Sprintf("%s, %s left", hold.reason, time.Until(hold.until).Round(time.Second))
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// AddAssistant starts a new assistant from a session string of the given type without restarting the bot.
+// It returns the new assistant's name. The session is not saved, so it must also be added to the
+// STRING variables to survive a restart.
+func (c *TelegramCalls) AddAssistant(sessionType, session string) (string, error) {
+	if sessionType == "" {
+		sessionType = config.Conf.SessionType
+	}
+	name, _, err := c.addClient(clientSession{
+		apiID:       config.Conf.ApiId,
+		apiHash:     config.Conf.ApiHash,
+		sessionType: sessionType,
+		session:     session,
+	})
+	return name, err
+}
+
+// DrainAssistant stops an assistant from taking new chats and moves its chats to other assistants.
+// Chats with a live call move at once; the others move the next time they use their assistant.
+func (c *TelegramCalls) DrainAssistant(name string) error {
+	if err := c.requireAssistant(name); err != nil {
+		return err
+	}
+	if !c.hasOtherAssistant(name) {
+		return fmt.Errorf("%s is the only assistant in rotation", name)
+	}
+
+	setDrained(name, true)
+	c.failoverAssistant(name, "drained")
+	return nil
+}
+
+// UndrainAssistant puts a drained assistant back into rotation.
+func (c *TelegramCalls) UndrainAssistant(name string) error {
+	if err := c.requireAssistant(name); err != nil {
+		return err
+	}
+
+	setDrained(name, false)
+	return nil
+}
+
+// RemoveAssistant drains an assistant, then stops its client and removes it from the pool.
+func (c *TelegramCalls) RemoveAssistant(name string) error {
+	if err := c.DrainAssistant(name); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	call, client := c.uBContext[name], c.clients[name]
+	delete(c.uBContext, name)
+	delete(c.clients, name)
+	delete(c.sessions, name)
+	c.availableClients = slices.DeleteFunc(c.availableClients, func(n string) bool { return n == name })
+	c.mu.Unlock()
+
+	setDrained(name, false)
+	call.Close()
+	_ = client.Stop()
+	logger.Info("[RemoveAssistant] The assistant %s has been removed.", name)
+	return nil
+}
+
+// RestartAssistant restarts one assistant's client under the same name, so its chats keep it,
+// and resumes the streams that were live on it.
+func (c *TelegramCalls) RestartAssistant(name string) error {
+	// The call leaves the pool before it is closed, so nothing picks up the old instance while the new client logs in.
+	c.mu.Lock()
+	call, client, sess := c.uBContext[name], c.clients[name], c.sessions[name]
+	if call == nil {
+		c.mu.Unlock()
+		return fmt.Errorf("no assistant is named %s", name)
+	}
+	setRestarting(name, true)
+	delete(c.uBContext, name)
+	delete(c.clients, name)
+	c.mu.Unlock()
+	defer setRestarting(name, false)
+
+	// The live chats' players stay locked while the call is torn down, so a command already running on it finishes first.
+	chats := slices.Sorted(maps.Keys(call.Calls()))
+	unlocks := make([]func(), 0, len(chats))
+	for _, chatID := range chats {
+		unlocks = append(unlocks, lockPlayer(chatID))
+	}
+	live := make(map[int64]int, len(chats))
+	for _, chatID := range chats {
+		live[chatID] = livePosition(chatID, call)
+		stopExternalPlayer(chatID)
+	}
+	call.Close()
+	_ = client.Stop()
+	for _, unlock := range unlocks {
+		unlock()
+	}
+	// The private calls were dropped with the old instance and cannot be rejoined.
+	c.endPrivateCalls(name)
+
+	mtProto, newCall, err := c.startClient(name, sess)
+
+	c.mu.Lock()
+	if err != nil {
+		// Without a client the name leaves the pool, and its chats move the next time they use their assistant.
+		delete(c.sessions, name)
+		c.availableClients = slices.DeleteFunc(c.availableClients, func(n string) bool { return n == name })
+		c.mu.Unlock()
+		return fmt.Errorf("failed to restart %s: %w", name, err)
+	}
+	c.uBContext[name] = newCall
+	c.clients[name] = mtProto
+	if c.bot != nil {
+		c.registerCallHandlers(newCall)
+	}
+	c.mu.Unlock()
+
+	logger.Info("[RestartAssistant] The assistant %s has been restarted.", name)
+	for chatID, position := range live {
+		song := cache.ChatCache.GetPlayingTrack(chatID)
+		if song == nil {
+			continue
+		}
+		if err := c.PlayMedia(chatID, song.FilePath, song.IsVideo, position); err != nil {
+			logger.Warn("[RestartAssistant] Failed to resume chat %d: %v", chatID, err)
+		}
+	}
+	return nil
+}
+
+// requireAssistant returns an error if no assistant has the given name.
+func (c *TelegramCalls) requireAssistant(name string) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if _, ok := c.uBContext[name]; !ok {
+		return fmt.Errorf("no assistant is named %s", name)
+	}
+	return nil
+}
+
+// hasOtherAssistant reports whether an assistant other than name is in rotation.
+func (c *TelegramCalls) hasOtherAssistant(name string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, other := range c.availableClients {
+		if other != name && !isDrained(other) {
+			return true
+		}
+	}
+	return false
+}
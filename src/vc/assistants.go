@@ -0,0 +1,421 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package vc
+
+import (
+	"crypto/rand"
+	"fmt"
+	"hash/fnv"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc/ubot"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+const (
+	// bannedHold is how long an assistant whose account was banned or logged out is left out of new assignments.
+	bannedHold = 24 * time.Hour
+	// limitedHold is how long an assistant Telegram has limited for spam is left out of new assignments.
+	limitedHold = time.Hour
+	// longFloodWait is the flood wait from which an assistant counts as failed and its chats move to other assistants.
+	longFloodWait = 5 * time.Minute
+	// chatCountsTTL is how long the per-assistant chat counts of the least_chats strategy are reused.
+	chatCountsTTL = 30 * time.Second
+)
+
+// Reasons an assistant is held, as logged and shown to the chats that move off it.
+const (
+	holdFlood   = "flood wait"
+	holdBanned  = "banned"
+	holdLimited = "limited for spam"
+)
+
+// assistantStrategy picks the assistant of a new chat from candidates, which is never empty.
+// It runs with c.mu held for reading.
+type assistantStrategy func(c *TelegramCalls, chatID int64, candidates []string) string
+
+// assistantStrategies maps the ASSISTANT_STRATEGY values to their strategies.
+var assistantStrategies = map[string]assistantStrategy{
+	config.StrategyLeastCalls: leastCallsStrategy,
+	config.StrategyLeastChats: leastChatsStrategy,
+	config.StrategyHash:       hashStrategy,
+	config.StrategyRandom:     randomStrategy,
+}
+
+// pickAssistant chooses the assistant of a chat with the configured strategy, never choosing exclude or an
+// assistant that is drained or banned from the chat. Held assistants are skipped unless every other assistant is held.
+// It returns an empty string if no assistant can serve the chat, and runs with c.mu held for reading.
+func (c *TelegramCalls) pickAssistant(chatID int64, exclude string) string {
+	var candidates, held []string
+	for _, name := range c.availableClients {
+		switch {
+		case name == exclude || isDrained(name) || isRestarting(name) || bannedInChat(chatID, name):
+		case assistantHeld(name):
+			held = append(held, name)
+		default:
+			candidates = append(candidates, name)
+		}
+	}
+	if len(candidates) == 0 {
+		if len(held) == 0 {
+			return ""
+		}
+		logger.Warn("[pickAssistant] Every assistant is held; choosing among the held ones for chat %d", chatID)
+		candidates = held
+	}
+
+	strategy, ok := assistantStrategies[config.Conf.AssistantStrategy]
+	if !ok {
+		strategy = leastChatsStrategy
+	}
+	return strategy(c, chatID, candidates)
+}
+
+// leastCallsStrategy picks the assistant with the fewest active calls.
+func leastCallsStrategy(c *TelegramCalls, _ int64, candidates []string) string {
+	best, bestCalls := candidates[0], -1
+	for _, name := range candidates {
+		calls := 0
+		if call, ok := c.uBContext[name]; ok {
+			calls = len(call.Calls())
+		}
+		if bestCalls < 0 || calls < bestCalls {
+			best, bestCalls = name, calls
+		}
+	}
+	return best
+}
+
+// chatCounts caches how many chats are assigned to each assistant, so assignments do not each
+// run an aggregate over every chat while c.mu is held.
+var chatCounts = struct {
+	sync.Mutex
+	byName  map[string]int
+	fetched time.Time
+}{}
+
+// leastChatsStrategy picks the assistant with the fewest chats assigned in the database,
+// falling back to the fewest active calls if the chats cannot be counted.
+// The counts are fetched at most once per chatCountsTTL and kept up to date with the assignments made since.
+func leastChatsStrategy(c *TelegramCalls, chatID int64, candidates []string) string {
+	chatCounts.Lock()
+	defer chatCounts.Unlock()
+
+	if chatCounts.byName == nil || time.Since(chatCounts.fetched) > chatCountsTTL {
+		ctx, cancel := db.Ctx()
+		counts, err := db.Instance.CountAssistantChats(ctx)
+		cancel()
+		if err != nil {
+			logger.Warn("[leastChatsStrategy] Failed to count the chats of each assistant: %v", err)
+			return leastCallsStrategy(c, chatID, candidates)
+		}
+		chatCounts.byName, chatCounts.fetched = counts, time.Now()
+	}
+
+	best := candidates[0]
+	for _, name := range candidates[1:] {
+		if chatCounts.byName[name] < chatCounts.byName[best] {
+			best = name
+		}
+	}
+	chatCounts.byName[best]++
+	return best
+}
+
+// hashStrategy picks the assistant with the highest hash of the chat ID and its name (rendezvous hashing).
+// A chat keeps hashing to the same assistant while it is available, and adding or holding an assistant
+// only moves the chats that hash to it.
+func hashStrategy(_ *TelegramCalls, chatID int64, candidates []string) string {
+	key := strconv.FormatInt(chatID, 10)
+	best, bestWeight := candidates[0], uint64(0)
+	for _, name := range candidates {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(name + ":" + key))
+		if weight := h.Sum64(); weight >= bestWeight {
+			best, bestWeight = name, weight
+		}
+	}
+	return best
+}
+
+// randomStrategy picks an assistant uniformly at random.
+func randomStrategy(_ *TelegramCalls, _ int64, candidates []string) string {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(len(candidates))))
+	if err != nil {
+		logger.Warn("[randomStrategy] Could not generate a random number: %v", err)
+		return candidates[0]
+	}
+	return candidates[n.Int64()]
+}
+
+// assistantHold keeps an assistant out of new assignments until a given time.
+type assistantHold struct {
+	until  time.Time
+	reason string
+}
+
+// failed reports whether the hold is long or serious enough for the assistant's chats to move to other assistants.
+// A spam limit only stops the assistant from joining new chats, so its chats keep it.
+func (h assistantHold) failed() bool {
+	switch h.reason {
+	case holdLimited:
+		return false
+	case holdFlood:
+		return time.Until(h.until) >= longFloodWait
+	}
+	return true
+}
+
+// assistantHolds holds the assistants that are temporarily flood-waited or banned.
+var assistantHolds = struct {
+	sync.Mutex
+	byName map[string]assistantHold
+}{byName: make(map[string]assistantHold)}
+
+// chatBans holds, for each chat, the assistants banned from it that the bot could not unban.
+var chatBans = struct {
+	sync.Mutex
+	byChat map[int64]map[string]bool
+}{byChat: make(map[int64]map[string]bool)}
+
+// reassignMu serialises reassignments, so a chat moves and is told about it only once.
+var reassignMu sync.Mutex
+
+// holdAssistant leaves an assistant out of new assignments for d, unless it is already held for longer.
+// It returns the hold now in place.
+func holdAssistant(name string, d time.Duration, reason string) assistantHold {
+	assistantHolds.Lock()
+	defer assistantHolds.Unlock()
+
+	hold := assistantHold{until: time.Now().Add(d), reason: reason}
+	if current, ok := assistantHolds.byName[name]; ok && current.until.After(hold.until) {
+		return current
+	}
+	assistantHolds.byName[name] = hold
+	logger.Warn("[holdAssistant] The assistant %s is held for %s: %s", name, d, reason)
+	return hold
+}
+
+// getAssistantHold returns the assistant's hold, if it is held.
+func getAssistantHold(name string) (assistantHold, bool) {
+	assistantHolds.Lock()
+	defer assistantHolds.Unlock()
+
+	hold, ok := assistantHolds.byName[name]
+	if ok && time.Now().After(hold.until) {
+		delete(assistantHolds.byName, name)
+		return assistantHold{}, false
+	}
+	return hold, ok
+}
+
+// assistantHeld reports whether an assistant is currently left out of new assignments.
+func assistantHeld(name string) bool {
+	_, ok := getAssistantHold(name)
+	return ok
+}
+
+// banAssistantInChat records that an assistant is banned from a chat and cannot be unbanned.
+func banAssistantInChat(chatID int64, name string) {
+	chatBans.Lock()
+	defer chatBans.Unlock()
+
+	if chatBans.byChat[chatID] == nil {
+		chatBans.byChat[chatID] = make(map[string]bool)
+	}
+	chatBans.byChat[chatID][name] = true
+}
+
+// bannedInChat reports whether an assistant is banned from a chat.
+func bannedInChat(chatID int64, name string) bool {
+	chatBans.Lock()
+	defer chatBans.Unlock()
+	return chatBans.byChat[chatID][name]
+}
+
+// assistantFailure returns why an assistant cannot serve a chat, or an empty string if it can.
+// It runs with c.mu held for reading.
+func (c *TelegramCalls) assistantFailure(chatID int64, name string) string {
+	// A client that failed to start is never registered, so its chats move on the next use.
+	// A restarting client keeps its chats.
+	if _, ok := c.uBContext[name]; !ok && !isRestarting(name) {
+		return "not running"
+	}
+	if isDrained(name) {
+		return "drained"
+	}
+	if bannedInChat(chatID, name) {
+		return "banned in this chat"
+	}
+	if hold, ok := getAssistantHold(name); ok && hold.failed() {
+		return hold.reason
+	}
+	return ""
+}
+
+// reassignChat moves a chat off an assistant that failed, saves its new assistant and tells the chat why.
+// It returns the chat's assistant afterwards, which stays from if no other assistant can serve the chat.
+// It runs with c.mu held for reading.
+func (c *TelegramCalls) reassignChat(chatID int64, from, reason string) string {
+	reassignMu.Lock()
+	defer reassignMu.Unlock()
+
+	ctx, cancel := db.Ctx()
+	defer cancel()
+
+	// Another caller may have moved the chat while this one waited.
+	if current, _ := db.Instance.GetAssistant(ctx, chatID); current != "" && current != from && c.assistantFailure(chatID, current) == "" {
+		return current
+	}
+
+	to := c.pickAssistant(chatID, from)
+	if to == "" {
+		logger.Warn("[reassignChat] No assistant can replace %s in chat %d", from, chatID)
+		return from
+	}
+	if err := db.Instance.SetAssistant(ctx, chatID, to); err != nil {
+		logger.Warn("[reassignChat] DB.SetAssistant error: %v", err)
+	}
+
+	logger.Info("[reassignChat] Chat %d moved from %s to %s: %s", chatID, from, to, reason)
+	go func() {
+		ctx, cancel := db.Ctx()
+		defer cancel()
+		text := fmt.Sprintf(lang.GetString(db.Instance.GetLang(ctx, chatID), "assistant_reassigned"), reason)
+		_, _ = c.bot.SendMessage(chatID, text)
+	}()
+	return to
+}
+
+// moveBannedAssistant records that call's assistant is banned from a chat and moves the chat to another assistant.
+// It returns false if no other assistant can serve the chat.
+func (c *TelegramCalls) moveBannedAssistant(chatID int64, call *ubot.Context) bool {
+	name := c.assistantName(call)
+	if name == "" {
+		return false
+	}
+	banAssistantInChat(chatID, name)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.reassignChat(chatID, name, "banned in this chat") != name
+}
+
+// failoverAssistant moves every chat with a live call on a failed assistant to another assistant and resumes its
+// stream there. Chats without a live call move the next time they use their assistant.
+func (c *TelegramCalls) failoverAssistant(name, reason string) {
+	c.mu.RLock()
+	old := c.uBContext[name]
+	c.mu.RUnlock()
+	if old == nil {
+		return
+	}
+
+	for chatID := range old.Calls() {
+		c.moveLiveChat(chatID, old, name, reason)
+	}
+}
+
+// moveLiveChat stops a chat's live call on the failed assistant old, moves the chat to another assistant
+// and resumes its stream there, as one command of the chat's player.
+func (c *TelegramCalls) moveLiveChat(chatID int64, old *ubot.Context, name, reason string) {
+	defer lockPlayer(chatID)()
+	position := livePosition(chatID, old)
+	stopExternalPlayer(chatID)
+	_ = old.Stop(chatID)
+
+	// A private call cannot move to another assistant, so it ends with the failed one.
+	if privateAssistantName(chatID) == name {
+		c.forgetPrivateCall(chatID, name)
+		return
+	}
+
+	c.mu.RLock()
+	to := c.reassignChat(chatID, name, reason)
+	c.mu.RUnlock()
+	if to == name {
+		return
+	}
+
+	song := cache.ChatCache.GetPlayingTrack(chatID)
+	if song == nil {
+		return
+	}
+	if err := c.playMedia(chatID, song.FilePath, song.IsVideo, position, streamStart{}); err != nil {
+		logger.Warn("[failoverAssistant] Failed to resume chat %d on %s: %v", chatID, to, err)
+	}
+}
+
+// livePosition returns the track position of a chat's stream on the given assistant, or 0 if it is unknown.
+func livePosition(chatID int64, call *ubot.Context) int {
+	state := cache.ChatCache.GetPlayback(chatID)
+	if p := getExternalPlayer(chatID); p != nil {
+		return trackPosition(state, p.streamed())
+	}
+	streamed, err := call.Time(chatID, 0)
+	if err != nil {
+		return 0
+	}
+	return trackPosition(state, float64(streamed))
+}
+
+// noteAssistantError holds an assistant whose request failed with a flood wait or because its account was banned,
+// and moves its live chats if the failure is serious.
+func (c *TelegramCalls) noteAssistantError(name string, err error) {
+	if err == nil || name == "" {
+		return
+	}
+
+	var hold assistantHold
+	msg := err.Error()
+	switch wait := tg.GetFloodWait(err); {
+	case wait > 0:
+		hold = holdAssistant(name, time.Duration(wait)*time.Second, holdFlood)
+	case strings.Contains(msg, "USER_DEACTIVATED"), strings.Contains(msg, "AUTH_KEY_UNREGISTERED"),
+		strings.Contains(msg, "SESSION_REVOKED"):
+		hold = holdAssistant(name, bannedHold, holdBanned)
+	case strings.Contains(msg, "PEER_FLOOD"):
+		hold = holdAssistant(name, limitedHold, holdLimited)
+	default:
+		return
+	}
+	if hold.failed() {
+		go c.failoverAssistant(name, hold.reason)
+	}
+}
+
+// floodHandler returns the flood handler of an assistant's client, which holds the assistant while it waits.
+func (c *TelegramCalls) floodHandler(name string) func(err error) bool {
+	return func(err error) bool {
+		c.noteAssistantError(name, err)
+		return handleFlood(err)
+	}
+}
+
+// assistantName returns the name of the client behind call, or an empty string if it is unknown.
+func (c *TelegramCalls) assistantName(call *ubot.Context) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for name, ctx := range c.uBContext {
+		if ctx == call {
+			return name
+		}
+	}
+	return ""
+}
@@ -22,22 +22,14 @@ import "C"
 
 import (
 	"context"
-	"crypto/rand"
 	"errors"
 	"fmt"
-	"log"
-	"math/big"
-	"os"
-	"path/filepath"
-	"regexp"
-	"strings"
 	"time"
 
 	"ashokshau/tgmusic/src/config"
 	"ashokshau/tgmusic/src/core"
 	"ashokshau/tgmusic/src/core/cache"
 	"ashokshau/tgmusic/src/core/db"
-	"ashokshau/tgmusic/src/core/dl"
 	"ashokshau/tgmusic/src/lang"
 	"ashokshau/tgmusic/src/vc/ntgcalls"
 	"ashokshau/tgmusic/src/vc/sessions"
@@ -47,10 +39,8 @@ import (
 )
 
 // getClientName selects an assistant client for a given chat. It prioritizes existing assignments from the database.
-// If no assignment exists, it randomly selects an available client and saves the assignment for future use.
-//
-// TODO: Implement a more sophisticated client selection strategy, such as consistent hashing or load-based balancing,
-// to ensure a more even distribution of chats among assistants.
+// If no assignment exists, it picks a client with the configured strategy and saves the assignment for future use.
+// A chat whose assistant has failed is moved to a healthy one.
 func (c *TelegramCalls) getClientName(chatID int64) (string, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -66,19 +56,16 @@ func (c *TelegramCalls) getClientName(chatID int64) (string, error) {
 	}
 
 	if assistant != "" {
-		for _, name := range c.availableClients {
-			if name == assistant {
-				return name, nil
-			}
+		if reason := c.assistantFailure(chatID, assistant); reason != "" {
+			return c.reassignChat(chatID, assistant, reason), nil
 		}
+		return assistant, nil
 	}
 
-	n, err := rand.Int(rand.Reader, big.NewInt(int64(len(c.availableClients))))
-	if err != nil {
-		log.Printf("[TelegramCalls] Could not generate a random number: %v", err)
-		return c.availableClients[0], nil
+	newClient := c.pickAssistant(chatID, "")
+	if newClient == "" {
+		return "", fmt.Errorf("no clients are available")
 	}
-	newClient := c.availableClients[n.Int64()]
 
 	if err = db.Instance.SetAssistant(ctx, chatID, newClient); err != nil {
 		c.bot.Log.Info("[TelegramCalls] DB.SetAssistant error: %v", err)
@@ -90,17 +77,29 @@ func (c *TelegramCalls) getClientName(chatID int64) (string, error) {
 
 // GetGroupAssistant retrieves the ubot.Context for a given chat, which is used to interact with the voice call.
 func (c *TelegramCalls) GetGroupAssistant(chatID int64) (*ubot.Context, error) {
+	// A private call stays with the assistant the user called.
+	if name := privateAssistantName(chatID); name != "" {
+		return c.assistantByName(name)
+	}
+
 	clientName, err := c.getClientName(chatID)
 	if err != nil {
 		return nil, err
 	}
+	return c.assistantByName(clientName)
+}
 
+// assistantByName returns the running ntgcalls instance of the named assistant.
+func (c *TelegramCalls) assistantByName(name string) (*ubot.Context, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	call, ok := c.uBContext[clientName]
+	call, ok := c.uBContext[name]
 	if !ok {
-		return nil, fmt.Errorf("no ntgcalls instance was found for %s", clientName)
+		if isRestarting(name) {
+			return nil, fmt.Errorf("the assistant %s is restarting", name)
+		}
+		return nil, fmt.Errorf("no ntgcalls instance was found for %s", name)
 	}
 	return call, nil
 }
@@ -109,67 +108,95 @@ func (c *TelegramCalls) GetGroupAssistant(chatID int64) (*ubot.Context, error) {
 // It authenticates with Telegram using the provided API ID, API hash, and session string.
 // The session type is determined by the configuration (pyrogram, telethon, or gogram).
 func (c *TelegramCalls) StartClient(apiID int32, apiHash, stringSession string) (*ubot.Context, error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	_, call, err := c.addClient(clientSession{apiID: apiID, apiHash: apiHash, sessionType: config.Conf.SessionType, session: stringSession})
+	return call, err
+}
 
+// clientSession holds what an assistant's client is started from, so it can be restarted.
+type clientSession struct {
+	apiID       int32
+	apiHash     string
+	sessionType string
+	session     string
+}
+
+// addClient starts a client under the next free name and adds it to the pool of available assistants.
+// A client added after the handlers are registered gets its call handlers at once.
+func (c *TelegramCalls) addClient(sess clientSession) (string, *ubot.Context, error) {
+	// The name is reserved under the lock, but the login runs without it so the pool stays usable meanwhile.
+	c.mu.Lock()
 	clientName := fmt.Sprintf("client%d", c.clientCounter)
-	var sess *tg.Session
-	var err error
+	c.clientCounter++
+	c.mu.Unlock()
+
+	mtProto, call, err := c.startClient(clientName, sess)
+	if err != nil {
+		return "", nil, err
+	}
+
+	c.mu.Lock()
+	c.uBContext[clientName] = call
+	c.clients[clientName] = mtProto
+	c.sessions[clientName] = sess
+	c.availableClients = append(c.availableClients, clientName)
+	if c.bot != nil {
+		c.registerCallHandlers(call)
+	}
+	c.mu.Unlock()
+
+	mtProto.Logger.Info("[TelegramCalls] client %s has started successfully.", clientName)
+	return clientName, call, nil
+}
 
+// startClient starts an MTProto client and its ntgcalls instance from a session string.
+func (c *TelegramCalls) startClient(clientName string, sess clientSession) (*tg.Client, *ubot.Context, error) {
 	clientConfig := tg.ClientConfig{
-		AppID:         apiID,
-		AppHash:       apiHash,
+		AppID:         sess.apiID,
+		AppHash:       sess.apiHash,
 		MemorySession: true,
 		SessionName:   clientName,
-		FloodHandler:  handleFlood,
+		FloodHandler:  c.floodHandler(clientName),
 	}
 
-	switch config.Conf.SessionType {
+	switch sess.sessionType {
 	case "telethon":
-		sess, err = sessions.DecodeTelethonSessionString(stringSession)
+		decoded, err := sessions.DecodeTelethonSessionString(sess.session)
 		if err != nil {
-			return nil, fmt.Errorf("failed to decode telethon session string for %s: %w", clientName, err)
+			return nil, nil, fmt.Errorf("failed to decode telethon session string for %s: %w", clientName, err)
 		}
-		clientConfig.StringSession = sess.Encode()
+		clientConfig.StringSession = decoded.Encode()
 	case "pyrogram":
-		sess, err = sessions.DecodePyrogramSessionString(stringSession)
+		decoded, err := sessions.DecodePyrogramSessionString(sess.session)
 		if err != nil {
-			return nil, fmt.Errorf("failed to decode pyrogram session string for %s: %w", clientName, err)
+			return nil, nil, fmt.Errorf("failed to decode pyrogram session string for %s: %w", clientName, err)
 		}
-		clientConfig.StringSession = sess.Encode()
+		clientConfig.StringSession = decoded.Encode()
 	case "gogram":
-		clientConfig.StringSession = stringSession
+		clientConfig.StringSession = sess.session
 	default:
-		return nil, fmt.Errorf("unsupported session type: %s", config.Conf.SessionType)
+		return nil, nil, fmt.Errorf("unsupported session type: %s", sess.sessionType)
 	}
 
 	mtProto, err := tg.NewClient(clientConfig)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create the MTProto client: %w", err)
+		return nil, nil, fmt.Errorf("failed to create the MTProto client: %w", err)
 	}
 
 	if err := mtProto.Start(); err != nil {
-		return nil, fmt.Errorf("failed to start the client: %w", err)
+		return nil, nil, fmt.Errorf("failed to start the client: %w", err)
 	}
 
 	if mtProto.Me().Bot {
 		_ = mtProto.Stop()
-		return nil, fmt.Errorf("the client %s is a bot", clientName)
+		return nil, nil, fmt.Errorf("the client %s is a bot", clientName)
 	}
 
 	call, err := ubot.NewInstance(mtProto)
 	if err != nil {
 		_ = mtProto.Stop()
-		return nil, fmt.Errorf("failed to create the ubot instance: %w", err)
+		return nil, nil, fmt.Errorf("failed to create the ubot instance: %w", err)
 	}
-
-	c.uBContext[clientName] = call
-	c.clients[clientName] = mtProto
-	c.availableClients = append(c.availableClients, clientName)
-	c.clientCounter++
-
-	mtProto.Logger.Info("[TelegramCalls] client %s has started successfully.", clientName)
-	return call, nil
+	return mtProto, call, nil
 }
 
 // StopAllClients gracefully stops all active userbot clients and their associated voice calls.
@@ -187,9 +214,55 @@ func (c *TelegramCalls) StopAllClients() {
 	}
 }
 
-// PlayMedia starts playing a media file in a voice chat. It handles joining the assistant to the chat if necessary
-// and sends a log message if logging is enabled.
-func (c *TelegramCalls) PlayMedia(chatID int64, filePath string, video bool, ffmpegParameters string) error {
+// startStream plays a stream built from state on the call, with the external engine or ntgcalls' shell source.
+// Each stream starts a new generation of the chat's player, so ends reported for earlier streams are ignored.
+func (c *TelegramCalls) startStream(chatID int64, call *ubot.Context, filePath string, video bool, state cache.PlaybackState) error {
+	gen, wasPaused := getPlayer(chatID).nextGeneration()
+	var err error
+	if useExternalEngine(video) {
+		forgetLiveMedia(chatID)
+		err = c.playExternal(chatID, call, filePath, state, GetQualityProfile(chatID), gen)
+	} else {
+		// ntgcalls takes the audio back from the external engine, so its player must not keep sending frames.
+		stopExternalPlayer(chatID)
+		desc := getMediaDescription(filePath, video, state, GetQualityProfile(chatID))
+		if err = call.Play(chatID, desc); err == nil {
+			setLiveMedia(chatID, desc)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	c.streamStarted(chatID, call, wasPaused)
+	return nil
+}
+
+// PlayMedia starts playing a media file in a voice chat from the given track position, applying the chat's
+// playback state. It handles joining the assistant to the chat if necessary and sends a log message if logging is enabled.
+func (c *TelegramCalls) PlayMedia(chatID int64, filePath string, video bool, offset int) error {
+	defer lockPlayer(chatID)()
+	return c.playMedia(chatID, filePath, video, offset, streamStart{})
+}
+
+// streamStart holds what only the first process of a track mixes in: a crossfade from the previous track
+// and a jingle or announcement. Seeks and other restarts play the track alone.
+type streamStart struct {
+	fade    *cache.Fade
+	overlay *cache.Overlay
+}
+
+// StartTrack plays song from its beginning, mixing in the chat's jingle or announcement if it has one.
+// The intro is prepared before the chat's player is taken, since rendering it may take a while.
+func (c *TelegramCalls) StartTrack(chatID int64, song *cache.CachedTrack) error {
+	overlay := c.trackIntro(chatID, song)
+	defer lockPlayer(chatID)()
+	return c.playMedia(chatID, song.FilePath, song.IsVideo, 0, streamStart{overlay: overlay})
+}
+
+// playMedia is PlayMedia with the extras of a track's first process, for callers that already run a command
+// of the chat's player.
+func (c *TelegramCalls) playMedia(chatID int64, filePath string, video bool, offset int, start streamStart) error {
 	call, err := c.GetGroupAssistant(chatID)
 	if err != nil {
 		return err
@@ -199,18 +272,58 @@ func (c *TelegramCalls) PlayMedia(chatID int64, filePath string, video bool, ffm
 
 	if chatID < 0 {
 		if err := c.joinAssistant(chatID, call.App.Me().ID); err != nil {
+			// An assistant that is banned for good is replaced, and the stream starts on the new one.
+			var banned *chatBanError
+			if errors.As(err, &banned) && c.moveBannedAssistant(chatID, call) {
+				return c.playMedia(chatID, filePath, video, offset, start)
+			}
 			cache.ChatCache.ClearChat(chatID)
+			resetPlayer(chatID)
 			return err
 		}
 	} else {
 		_, _ = call.App.ResolvePeer(chatID)
 	}
 
-	c.bot.Log.Info("Playing media in chat %d: %s", chatID, filePath)
-	mediaDesc := getMediaDescription(filePath, video, ffmpegParameters)
-	if err := call.Play(chatID, mediaDesc); err != nil {
+	filePath = resolveProgressive(chatID, filePath)
+	volume := db.Instance.GetVolume(ctx, chatID)
+	loudnessTarget := db.Instance.GetLoudnessTarget(ctx, chatID)
+	song := cache.ChatCache.GetPlayingTrack(chatID)
+	trimStart, trimEnd := 0, 0
+	if song != nil {
+		trimStart, trimEnd = song.StartAt, song.EndAt
+	}
+	var loudness *cache.Loudness
+	if loudnessTarget != 0 {
+		loudness = trackLoudness(song, filePath)
+	}
+	screen := useScreen(chatID, song)
+	lead := 0.0
+	if start.overlay != nil && start.overlay.Intro {
+		lead = start.overlay.Length
+	}
+	applyState := func(state *cache.PlaybackState) {
+		state.Offset = offset
+		state.Lead = lead
+		state.Screen = screen
+		state.Volume = volume
+		state.LoudnessTarget, state.Loudness = loudnessTarget, loudness
+		state.TrimStart, state.TrimEnd = trimStart, trimEnd
+		// A repeated section narrows playback to itself until it is cleared.
+		if state.RepeatEnd > 0 {
+			state.TrimStart, state.TrimEnd = state.RepeatStart, state.RepeatEnd
+		}
+	}
+	cache.ChatCache.UpdatePlayback(chatID, applyState)
+	state := cache.ChatCache.GetPlayback(chatID)
+	applyState(&state)
+	state.Fade, state.Overlay = start.fade, start.overlay
+
+	c.bot.Log.Info("Playing media in chat %d from %ds: %s", chatID, offset, filePath)
+	if err = c.startStream(chatID, call, filePath, video, state); err != nil {
 		logger.Error("Failed to play the media: %v", err)
 		cache.ChatCache.ClearChat(chatID)
+		resetPlayer(chatID)
 		return fmt.Errorf("playback failed: %w", err)
 	}
 
@@ -223,7 +336,7 @@ func (c *TelegramCalls) PlayMedia(chatID int64, filePath string, video bool, ffm
 
 // downloadAndPrepareSong handles the download and preparation of a song for playback.
 // It returns an error if the download or preparation fails.
-func (c *TelegramCalls) downloadAndPrepareSong(song *cache.CachedTrack, reply *tg.NewMessage) error {
+func (c *TelegramCalls) downloadAndPrepareSong(chatID int64, song *cache.CachedTrack, reply *tg.NewMessage) error {
 	if song.FilePath != "" {
 		return nil
 	}
@@ -231,14 +344,29 @@ func (c *TelegramCalls) downloadAndPrepareSong(song *cache.CachedTrack, reply *t
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
 	defer cancel()
 
-	chatID := config.Conf.LoggerId
+	// A track prefetched for a crossfade is already downloading or done, so it is not downloaded again.
+	if p := takePrefetch(chatID, song.TrackID); p != nil {
+		select {
+		case <-p.done:
+		case <-ctx.Done():
+		}
+		if p.ready() {
+			song.FilePath = p.path
+			if p.duration > 0 {
+				song.Duration = p.duration
+			}
+			return nil
+		}
+	}
+
 	dbCtx, dbCancel := db.Ctx()
 	defer dbCancel()
 	langCode := db.Instance.GetLang(dbCtx, chatID)
 
-	dlPath, trackInfo, err := DownloadSong(ctx, song, c.bot)
+	dlPath, trackInfo, err := DownloadSong(ctx, chatID, song, c.bot)
 	if err != nil {
-		_, _ = reply.Edit(fmt.Sprintf(lang.GetString(langCode, "download_failed_skip"), err))
+		_, _ = reply.Edit(DownloadErrorText(langCode, err, false) + lang.GetString(langCode, "dl_error_skip"))
+		go sendDownloadError(c.bot, chatID, song, err)
 		return err
 	}
 
@@ -257,6 +385,12 @@ func (c *TelegramCalls) downloadAndPrepareSong(song *cache.CachedTrack, reply *t
 
 // PlayNext plays the next song in the queue, handles looping, and notifies the chat when the queue is finished.
 func (c *TelegramCalls) PlayNext(chatID int64) error {
+	defer lockPlayer(chatID)()
+	return c.playNext(chatID)
+}
+
+// playNext is PlayNext for callers that already run a command of the chat's player.
+func (c *TelegramCalls) playNext(chatID int64) error {
 	loop := cache.ChatCache.GetLoopCount(chatID)
 	if loop > 0 {
 		cache.ChatCache.SetLoopCount(chatID, loop-1)
@@ -277,7 +411,7 @@ func (c *TelegramCalls) PlayNext(chatID int64) error {
 // handleNoSong manages the situation where there are no more songs in the queue by stopping the playback
 // and sending a notification to the chat.
 func (c *TelegramCalls) handleNoSong(chatID int64) error {
-	_ = c.Stop(chatID)
+	_ = c.stop(chatID)
 	ctx, cancel := db.Ctx()
 	defer cancel()
 	langCode := db.Instance.GetLang(ctx, chatID)
@@ -287,7 +421,15 @@ func (c *TelegramCalls) handleNoSong(chatID int64) error {
 
 // playSong downloads and plays a single song. It sends a message to the chat to indicate the download status
 // and updates it with the song's information once playback begins.
+//
+// The chat's player is released while the track downloads and its intro renders, so /stop, /skip and the
+// buttons are not held up by a slow download; it is held again before playSong returns. If another command
+// moved the chat on in the meantime, the loaded track is dropped.
 func (c *TelegramCalls) playSong(chatID int64, song *cache.CachedTrack) error {
+	p := getPlayer(chatID)
+	// The previous stream may still be playing while the player is released, so its generation ends here:
+	// its end must not move the queue past the track being loaded.
+	gen, _ := p.nextGeneration()
 	ctx, cancel := db.Ctx()
 	defer cancel()
 	langCode := db.Instance.GetLang(ctx, chatID)
@@ -297,15 +439,39 @@ func (c *TelegramCalls) playSong(chatID int64, song *cache.CachedTrack) error {
 		return err
 	}
 
-	if err := c.downloadAndPrepareSong(song, reply); err != nil {
-		return c.PlayNext(chatID)
+	p.cmd.Unlock()
+	err = c.downloadAndPrepareSong(chatID, song, reply)
+	var overlay *cache.Overlay
+	if err == nil {
+		overlay = c.trackIntro(chatID, song)
 	}
+	p.cmd.Lock()
 
-	if err := c.PlayMedia(chatID, song.FilePath, song.IsVideo, ""); err != nil {
+	if p.currentGeneration() != gen || cache.ChatCache.GetPlayingTrack(chatID) != song {
+		logger.Info("[playSong] Dropping %s in chat %d, which moved on while it loaded", song.TrackID, chatID)
+		_, _ = reply.Delete()
+		return nil
+	}
+	if err != nil {
+		return c.playNext(chatID)
+	}
+
+	cache.ChatCache.UpdatePlayback(chatID, resetTrack)
+	if err := c.playMedia(chatID, song.FilePath, song.IsVideo, 0, streamStart{overlay: overlay}); err != nil {
 		_, err := reply.Edit(err.Error())
 		return err
 	}
 
+	c.showNowPlaying(chatID, song, reply)
+	return nil
+}
+
+// showNowPlaying edits reply into the now-playing message of song, with its thumbnail and the player controls.
+func (c *TelegramCalls) showNowPlaying(chatID int64, song *cache.CachedTrack, reply *tg.NewMessage) {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
 	if song.Duration == 0 {
 		song.Duration = cache.GetFileDuration(song.FilePath)
 	}
@@ -316,30 +482,41 @@ func (c *TelegramCalls) playSong(chatID int64, song *cache.CachedTrack) error {
 		song.Name,
 		cache.SecToMin(song.Duration),
 		song.User,
-	)
+	) + EffectLine(langCode, chatID)
 
 	thumb, _ := core.GenThumb(*song)
 
-	_, err = reply.Edit(text, &tg.SendOptions{
+	_, err := reply.Edit(text, &tg.SendOptions{
 		ReplyMarkup: core.ControlButtons("play"),
 		Media:       thumb,
 	})
 	if err != nil {
-		c.bot.Log.Warn("[playSong] Failed to edit message: %v", err)
-		return nil
+		c.bot.Log.Warn("[showNowPlaying] Failed to edit message: %v", err)
 	}
-
-	return nil
 }
 
 // Stop halts media playback in a voice chat and clears the chat's cache.
 func (c *TelegramCalls) Stop(chatId int64) error {
+	defer lockPlayer(chatId)()
+	return c.stop(chatId)
+}
+
+// stop is Stop for callers that already run a command of the chat's player.
+func (c *TelegramCalls) stop(chatId int64) error {
 	call, err := c.GetGroupAssistant(chatId)
 	if err != nil {
 		return err
 	}
-	cache.ChatCache.ClearChat(chatId)
-	err = call.Stop(chatId)
+	getPlayer(chatId).setState(PlayerStopping)
+	defer resetPlayer(chatId)
+
+	c.clearPlayback(chatId)
+	if IsRecording(chatId) || HasPrivateCall(chatId) {
+		// A recording or a private call keeps the assistant in the call, so only the music stops.
+		err = call.Play(chatId, ntgcalls.MediaDescription{})
+	} else {
+		err = call.Stop(chatId)
+	}
 	if err != nil {
 		c.bot.Log.Info("[Stop] Failed to stop the call: %v", err)
 		// For now, we will ignore the error.
@@ -348,85 +525,216 @@ func (c *TelegramCalls) Stop(chatId int64) error {
 	return nil
 }
 
+// clearPlayback clears the chat's queue and everything that runs alongside its playback.
+func (c *TelegramCalls) clearPlayback(chatId int64) {
+	cache.ChatCache.ClearChat(chatId)
+	dropPrefetch(chatId)
+	clearSleep(chatId)
+	c.clearIdle(chatId, false)
+	forgetStream(chatId)
+	forgetLiveMedia(chatId)
+	stopExternalPlayer(chatId)
+}
+
+// playerError returns the error for a command the chat's player cannot run in its current state.
+func playerError(chatID int64, key string) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	return errors.New(lang.GetString(db.Instance.GetLang(ctx, chatID), key))
+}
+
 // Pause temporarily stops media playback in a voice chat.
 // It returns true if the operation was successful, and an error otherwise.
 func (c *TelegramCalls) Pause(chatId int64) (bool, error) {
+	defer lockPlayer(chatId)()
 	call, err := c.GetGroupAssistant(chatId)
 	if err != nil {
 		return false, err
 	}
 
+	switch state, _ := c.GetPlayerState(chatId); state {
+	case PlayerPlaying:
+	case PlayerPaused:
+		return false, playerError(chatId, "player_already_paused")
+	default:
+		return false, playerError(chatId, "no_track_playing")
+	}
+
+	ok, err := c.pause(chatId, call)
+	if err != nil {
+		return false, err
+	}
+	getPlayer(chatId).setState(PlayerPaused)
+	return ok, nil
+}
+
+// pause holds the stream of a chat with whichever engine plays it.
+func (c *TelegramCalls) pause(chatId int64, call *ubot.Context) (bool, error) {
+	if p := getExternalPlayer(chatId); p != nil {
+		return !p.paused.Swap(true), nil
+	}
 	return call.Pause(chatId)
 }
 
 // Resume continues a paused media playback in a voice chat.
 // It returns true if the operation was successful, and an error otherwise.
 func (c *TelegramCalls) Resume(chatId int64) (bool, error) {
+	defer lockPlayer(chatId)()
 	call, err := c.GetGroupAssistant(chatId)
 	if err != nil {
 		return false, err
 	}
-	return call.Resume(chatId)
+
+	switch state, _ := c.GetPlayerState(chatId); state {
+	case PlayerPaused:
+	case PlayerPlaying:
+		return false, playerError(chatId, "player_not_paused")
+	default:
+		return false, playerError(chatId, "no_track_playing")
+	}
+
+	var ok bool
+	if p := getExternalPlayer(chatId); p != nil {
+		ok = p.paused.Swap(false)
+	} else if ok, err = call.Resume(chatId); err != nil {
+		return false, err
+	}
+	getPlayer(chatId).setState(PlayerPlaying)
+	return ok, nil
 }
 
 // Mute silences the media playback in a voice chat.
 // It returns true if the operation was successful, and an error otherwise.
 func (c *TelegramCalls) Mute(chatId int64) (bool, error) {
+	defer lockPlayer(chatId)()
 	call, err := c.GetGroupAssistant(chatId)
 	if err != nil {
 		return false, err
 	}
+
+	switch state, muted := c.GetPlayerState(chatId); {
+	case state != PlayerPlaying && state != PlayerPaused:
+		return false, playerError(chatId, "no_track_playing")
+	case muted:
+		return false, playerError(chatId, "player_already_muted")
+	}
+
+	ok, err := c.mute(chatId, call)
+	if err != nil {
+		return false, err
+	}
+	getPlayer(chatId).setMuted(true)
+	return ok, nil
+}
+
+// mute silences the stream of a chat with whichever engine plays it.
+func (c *TelegramCalls) mute(chatId int64, call *ubot.Context) (bool, error) {
+	if p := getExternalPlayer(chatId); p != nil {
+		return !p.muted.Swap(true), nil
+	}
 	return call.Mute(chatId)
 }
 
 // Unmute restores the audio of a muted media playback in a voice chat.
 // It returns true if the operation was successful, and an error otherwise.
 func (c *TelegramCalls) Unmute(chatId int64) (bool, error) {
+	defer lockPlayer(chatId)()
 	call, err := c.GetGroupAssistant(chatId)
 	if err != nil {
 		return false, err
 	}
-	return call.Unmute(chatId)
+
+	switch state, muted := c.GetPlayerState(chatId); {
+	case state != PlayerPlaying && state != PlayerPaused:
+		return false, playerError(chatId, "no_track_playing")
+	case !muted:
+		return false, playerError(chatId, "player_not_muted")
+	}
+
+	var ok bool
+	if p := getExternalPlayer(chatId); p != nil {
+		ok = p.muted.Swap(false)
+	} else if ok, err = call.Unmute(chatId); err != nil {
+		return false, err
+	}
+	getPlayer(chatId).setMuted(false)
+	return ok, nil
 }
 
-// PlayedTime retrieves the elapsed time of the current playback in a voice chat.
-// It returns the elapsed time in seconds and an error if any.
+// PlayedTime retrieves the position of the current track in a voice chat.
+// It accounts for the position the stream was started from and for speed changes, and returns the position in seconds.
 func (c *TelegramCalls) PlayedTime(chatId int64) (uint64, error) {
 	call, err := c.GetGroupAssistant(chatId)
 	if err != nil {
 		return 0, err
 	}
 
+	// The external engine counts the samples it sent, which is exact to the sample.
+	if p := getExternalPlayer(chatId); p != nil {
+		return uint64(trackPosition(cache.ChatCache.GetPlayback(chatId), p.streamed())), nil
+	}
+
 	// TODO: Pass the streamMode.
-	return call.Time(chatId, 0)
+	streamed, err := call.Time(chatId, 0)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(trackPosition(cache.ChatCache.GetPlayback(chatId), float64(streamed))), nil
 }
 
-var urlRegex = regexp.MustCompile(`^https?://`)
-
-// SeekStream jumps to a specific time in the current media stream.
+// SeekStream jumps to a specific time in the current media stream, keeping the chat's speed, volume and effect.
 func (c *TelegramCalls) SeekStream(chatID int64, filePath string, toSeek, duration int, isVideo bool) error {
 	ctx, cancel := db.Ctx()
 	defer cancel()
 	langCode := db.Instance.GetLang(ctx, chatID)
-	if toSeek < 0 || duration <= 0 {
+	if toSeek < 0 || duration <= 0 || toSeek >= duration {
 		return errors.New(lang.GetString(langCode, "invalid_seek"))
 	}
 
-	isURL := urlRegex.MatchString(filePath)
-	_, err := os.Stat(filePath)
-	isFile := err == nil
+	return c.PlayMedia(chatID, filePath, isVideo, toSeek)
+}
 
-	var ffmpegParams string
-	if isURL || !isFile {
-		ffmpegParams = fmt.Sprintf("-ss %d -i %s -to %d", toSeek, filePath, duration)
-	} else {
-		ffmpegParams = fmt.Sprintf("-ss %d -to %d", toSeek, duration)
+// SeekTo restarts the current track at position, clamped to the track's bounds, keeping the chat's speed, volume and effect.
+// It returns the position the track was restarted at.
+func (c *TelegramCalls) SeekTo(chatID int64, position int) (int, error) {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	playingSong := cache.ChatCache.GetPlayingTrack(chatID)
+	if playingSong == nil {
+		return 0, errors.New(lang.GetString(langCode, "no_song_playing"))
+	}
+	if playingSong.Duration <= 0 {
+		return 0, errors.New(lang.GetString(langCode, "invalid_seek"))
 	}
 
-	return c.PlayMedia(chatID, filePath, isVideo, ffmpegParams)
+	state := cache.ChatCache.GetPlayback(chatID)
+	end := playingSong.Duration
+	if state.TrimEnd > 0 {
+		end = min(end, state.TrimEnd)
+	}
+	// Seeking to the very end would end the stream at once, so the last second is the upper bound.
+	position = min(max(position, state.TrimStart), end-1)
+	position = max(position, 0)
+
+	return position, c.PlayMedia(chatID, playingSong.FilePath, playingSong.IsVideo, position)
+}
+
+// SeekStep is how many seconds the seek buttons move the current track by.
+const SeekStep = 10
+
+// SeekBy moves the current track by delta seconds from its current position, backwards for a negative delta.
+// It returns the position the track was restarted at.
+func (c *TelegramCalls) SeekBy(chatID int64, delta int) (int, error) {
+	played, err := c.PlayedTime(chatID)
+	if err != nil {
+		return 0, err
+	}
+	return c.SeekTo(chatID, int(played)+delta)
 }
 
-// ChangeSpeed modifies the playback speed of the current stream.
+// ChangeSpeed modifies the playback speed of the current stream and continues from the current position.
 func (c *TelegramCalls) ChangeSpeed(chatID int64, speed float64) error {
 	ctx, cancel := db.Ctx()
 	defer cancel()
@@ -440,24 +748,62 @@ func (c *TelegramCalls) ChangeSpeed(chatID int64, speed float64) error {
 		return errors.New(lang.GetString(langCode, "no_song_playing"))
 	}
 
-	videoPTS := 1 / speed
+	return c.restartWith(chatID, playingSong, func(state *cache.PlaybackState) {
+		state.Speed = speed
+	})
+}
 
-	audioFilters := make([]string, 0)
-	remaining := speed
-	for remaining > 2.0 {
-		audioFilters = append(audioFilters, "atempo=2.0")
-		remaining /= 2.0
+// restartWith applies update to the chat's playback state and restarts the current track at its current position.
+func (c *TelegramCalls) restartWith(chatID int64, song *cache.CachedTrack, update func(state *cache.PlaybackState)) error {
+	// The position depends on the old state, so it is read before the update.
+	position, err := c.PlayedTime(chatID)
+	if err != nil || (song.Duration > 0 && int(position) >= song.Duration) {
+		position = 0
 	}
-	for remaining < 0.5 {
-		audioFilters = append(audioFilters, "atempo=0.5")
-		remaining /= 0.5
+
+	cache.ChatCache.UpdatePlayback(chatID, update)
+	return c.PlayMedia(chatID, song.FilePath, song.IsVideo, int(position))
+}
+
+// handleStreamEnd moves a chat on once the audio of its current stream has ended,
+// whether ntgcalls or the external engine reported the end.
+// gen is the generation of the stream that ended; an end reported after a newer stream started is ignored,
+// since whatever started that stream has already moved the chat on.
+func (c *TelegramCalls) handleStreamEnd(chatID int64, gen uint64) {
+	p := getPlayer(chatID)
+	p.cmd.Lock()
+	defer p.cmd.Unlock()
+	if current := p.currentGeneration(); gen != current {
+		logger.Info("[handleStreamEnd] Ignoring the end of stream %d in chat %d, which now plays stream %d", gen, chatID, current)
+		return
 	}
-	audioFilters = append(audioFilters, fmt.Sprintf("atempo=%f", remaining))
-	audioFilter := strings.Join(audioFilters, ",")
 
-	ffmpegFilters := fmt.Sprintf("-filter:v setpts=%f*PTS -filter:a %s", videoPTS, audioFilter)
+	if greetingEnded(chatID) {
+		resetPlayer(chatID)
+		return
+	}
 
-	return c.PlayMedia(chatID, playingSong.FilePath, playingSong.IsVideo, ffmpegFilters)
+	if c.recoverProgressive(chatID, gen) {
+		return
+	}
+	c.advance(chatID)
+}
+
+// advance ends the chat's session, repeats its section or plays its next track once its current stream is over.
+// The caller runs a command of the chat's player.
+func (c *TelegramCalls) advance(chatID int64) {
+	if stopAfterTrack(chatID) {
+		c.endSession(chatID)
+		return
+	}
+
+	if c.repeatSection(chatID) {
+		return
+	}
+
+	if err := c.playNext(chatID); err != nil {
+		c.bot.Log.Error("[OnStreamEnd] Failed to play the song: %v", err)
+	}
 }
 
 // RegisterHandlers sets up the event handlers for the voice call client.
@@ -469,56 +815,59 @@ func (c *TelegramCalls) RegisterHandlers(client *tg.Client) {
 	logger = client.Log
 
 	for _, call := range c.uBContext {
+		c.registerCallHandlers(call)
+	}
 
-		//_, _ = call.App.UpdatesGetState()
-		call.OnStreamEnd(func(chatID int64, streamType ntgcalls.StreamType, device ntgcalls.StreamDevice) {
-			client.Log.Info("[TelegramCalls] The stream has ended in chat %d (type=%v, device=%v)", chatID, streamType, device)
-			if streamType == ntgcalls.VideoStream {
-				client.Log.Info("Ignoring video stream end for chat %d", chatID)
-				return
-			}
+	go c.watchCrossfades()
+	go c.watchIdle()
+	go c.watchStreams()
+}
 
-			if err := c.PlayNext(chatID); err != nil {
-				client.Log.Error("[OnStreamEnd] Failed to play the song: %v", err)
-			}
-		})
-
-		call.OnIncomingCall(func(ub *ubot.Context, chatID int64) {
-			ctx, cancel := db.Ctx()
-			defer cancel()
-			langCode := db.Instance.GetLang(ctx, chatID)
-			_, _ = ub.App.SendMessage(chatID, lang.GetString(langCode, "incoming_call"))
-			msg, err := dl.GetMessage(c.bot, "https://t.me/FallenSongs/1295")
-			if err != nil {
-				c.bot.Log.Info("[OnIncomingCall] Failed to get the message: %v", err)
-				return
-			}
+// registerCallHandlers sets up the call event handlers of one assistant. It runs with c.mu held.
+func (c *TelegramCalls) registerCallHandlers(call *ubot.Context) {
+	client := c.bot
 
-			dCtx, dCancel := context.WithTimeout(context.Background(), 1*time.Minute)
-			defer dCancel()
-			filePath, err := msg.Download(&tg.DownloadOptions{FileName: filepath.Join(config.Conf.DownloadsDir, msg.File.Name), Ctx: dCtx})
-			if err != nil {
-				c.bot.Log.Info("[OnIncomingCall] Failed to download the message: %v", err)
-				return
-			}
+	call.OnStreamEnd(func(chatID int64, streamType ntgcalls.StreamType, device ntgcalls.StreamDevice) {
+		// The generation is read first, so a command that starts a new stream meanwhile makes this end stale.
+		gen := getPlayer(chatID).currentGeneration()
+		client.Log.Info("[TelegramCalls] The stream has ended in chat %d (type=%v, device=%v)", chatID, streamType, device)
+		if streamType == ntgcalls.VideoStream {
+			client.Log.Info("Ignoring video stream end for chat %d", chatID)
+			return
+		}
 
-			err = c.PlayMedia(chatID, filePath, false, "")
-			if err != nil {
-				c.bot.Log.Info("[OnIncomingCall] Failed to play the media: %v", err)
-				return
-			}
+		c.handleStreamEnd(chatID, gen)
+	})
 
-			return
-		})
+	call.OnIncomingCall(func(ub *ubot.Context, chatID int64) {
+		c.handleIncomingCall(ub, chatID)
+	})
 
-		call.OnFrame(func(chatId int64, mode ntgcalls.StreamMode, device ntgcalls.StreamDevice, frames []ntgcalls.Frame) {
-			c.bot.Log.Debug("Received frames for chatId: %d, mode: %v, device: %v", chatId, mode, device)
-		})
+	call.OnCallDiscarded(func(ub *ubot.Context, chatID int64) {
+		c.endPrivateCall(ub, chatID)
+	})
 
-		_, _ = call.App.SendMessage(client.Me().Username, "/start")
-		_, err := call.App.SendMessage(config.Conf.LoggerId, "UB has started.")
-		if err != nil {
-			c.bot.Log.Info("[TelegramCalls - SendMessage] Failed to send message: %v", err)
+	call.OnParticipantsChange(func(chatID int64) {
+		if cache.ChatCache.IsActive(chatID) {
+			c.checkIdle(chatID)
 		}
+	})
+
+	call.OnConnectionChange(func(chatID int64, info ntgcalls.NetworkInfo) {
+		c.handleConnectionChange(call, chatID, info)
+	})
+
+	call.OnFrame(func(chatId int64, mode ntgcalls.StreamMode, device ntgcalls.StreamDevice, frames []ntgcalls.Frame) {
+		if mode == ntgcalls.PlaybackStream && device == ntgcalls.SpeakerStream {
+			recordFrames(chatId, frames)
+			return
+		}
+		c.bot.Log.Debug("Received frames for chatId: %d, mode: %v, device: %v", chatId, mode, device)
+	})
+
+	_, _ = call.App.SendMessage(client.Me().Username, "/start")
+	_, err := call.App.SendMessage(config.Conf.LoggerId, "UB has started.")
+	if err != nil {
+		c.bot.Log.Info("[TelegramCalls - SendMessage] Failed to send message: %v", err)
 	}
 }
@@ -0,0 +1,212 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package vc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+)
+
+const (
+	// MaxCrossfade is the longest crossfade, in seconds, a chat can set.
+	MaxCrossfade = 12
+	// crossfadeCheckInterval is how often the active chats are checked for a track nearing its end.
+	crossfadeCheckInterval = time.Second
+	// prefetchTimeout bounds the download of an upcoming track.
+	prefetchTimeout = 3 * time.Minute
+)
+
+// prefetch is a download of a chat's upcoming track started while the current one still plays.
+type prefetch struct {
+	trackID  string
+	done     chan struct{}
+	path     string
+	duration int
+	err      error
+}
+
+// prefetches holds the latest prefetch of every chat.
+var prefetches = struct {
+	sync.Mutex
+	byChat map[int64]*prefetch
+}{byChat: make(map[int64]*prefetch)}
+
+// startPrefetch downloads song in the background, unless the chat is already downloading it.
+func (c *TelegramCalls) startPrefetch(chatID int64, song *cache.CachedTrack) *prefetch {
+	prefetches.Lock()
+	defer prefetches.Unlock()
+
+	if p, ok := prefetches.byChat[chatID]; ok && p.trackID == song.TrackID {
+		return p
+	}
+
+	p := &prefetch{trackID: song.TrackID, done: make(chan struct{})}
+	prefetches.byChat[chatID] = p
+	go func() {
+		defer close(p.done)
+		ctx, cancel := context.WithTimeout(context.Background(), prefetchTimeout)
+		defer cancel()
+
+		path, trackInfo, err := DownloadSong(ctx, chatID, song, c.bot)
+		if err != nil {
+			p.err = err
+			logger.Warn("[prefetch] Failed to download %s for chat %d: %v", song.TrackID, chatID, err)
+			return
+		}
+		p.path = path
+		if trackInfo != nil {
+			p.duration = trackInfo.Duration
+		}
+	}()
+	return p
+}
+
+// takePrefetch removes and returns the chat's prefetch of a track, or nil if the track was not prefetched.
+func takePrefetch(chatID int64, trackID string) *prefetch {
+	prefetches.Lock()
+	defer prefetches.Unlock()
+
+	p, ok := prefetches.byChat[chatID]
+	if !ok || p.trackID != trackID {
+		return nil
+	}
+	delete(prefetches.byChat, chatID)
+	return p
+}
+
+// dropPrefetch forgets the chat's prefetch, for example when playback stops.
+func dropPrefetch(chatID int64) {
+	prefetches.Lock()
+	defer prefetches.Unlock()
+	delete(prefetches.byChat, chatID)
+}
+
+// ready reports whether the prefetch finished successfully.
+func (p *prefetch) ready() bool {
+	select {
+	case <-p.done:
+		return p.err == nil && p.path != ""
+	default:
+		return false
+	}
+}
+
+// watchCrossfades checks every active chat once per interval and starts a crossfade when a track nears its end.
+func (c *TelegramCalls) watchCrossfades() {
+	ticker := time.NewTicker(crossfadeCheckInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for _, chatID := range cache.ChatCache.GetActiveChats() {
+			c.checkCrossfade(chatID)
+		}
+	}
+}
+
+// checkCrossfade prefetches the chat's upcoming track and, once the current one is within the crossfade length
+// of its end, mixes the upcoming track in. Video tracks, looped tracks, repeated sections and chats that stop
+// after the current track end normally.
+func (c *TelegramCalls) checkCrossfade(chatID int64) {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	seconds := db.Instance.GetCrossfade(ctx, chatID)
+	if seconds == 0 {
+		return
+	}
+
+	current := cache.ChatCache.GetPlayingTrack(chatID)
+	next := cache.ChatCache.GetUpcomingTrack(chatID)
+	if current == nil || next == nil || current.IsVideo || next.IsVideo || current.FilePath == "" {
+		return
+	}
+
+	state := cache.ChatCache.GetPlayback(chatID)
+	if state.RepeatEnd > 0 || cache.ChatCache.GetLoopCount(chatID) > 0 || stopsAfterTrack(chatID) {
+		return
+	}
+
+	p := c.startPrefetch(chatID, next)
+	end := current.Duration
+	if state.TrimEnd > 0 {
+		end = min(end, state.TrimEnd)
+	}
+	if end <= 2*seconds || !p.ready() {
+		return
+	}
+
+	played, err := c.PlayedTime(chatID)
+	if err != nil || int(played) < end-seconds || int(played) >= end {
+		return
+	}
+
+	c.crossfade(chatID, current, next, takePrefetch(chatID, next.TrackID), int(played), end)
+}
+
+// crossfade moves the queue to next and starts it mixed with the tail of current, from position to end.
+// The new stream starts at the beginning of next, so its position is reported the same way as a plain start.
+func (c *TelegramCalls) crossfade(chatID int64, current, next *cache.CachedTrack, p *prefetch, position, end int) {
+	if p == nil {
+		return
+	}
+
+	// The intro may take a while to render, so it is prepared before the chat's player is taken.
+	overlay := c.trackIntro(chatID, next)
+
+	// A skip or stream end may have moved the queue on while the position was read.
+	defer lockPlayer(chatID)()
+	if playing := cache.ChatCache.GetPlayingTrack(chatID); playing == nil || playing.TrackID != current.TrackID {
+		return
+	}
+
+	cache.ChatCache.RemoveCurrentSong(chatID)
+	next.FilePath = p.path
+	if p.duration > 0 {
+		next.Duration = p.duration
+	}
+	cache.ChatCache.UpdatePlayback(chatID, resetTrack)
+
+	fade := &cache.Fade{FilePath: current.FilePath, Start: position, End: end}
+	if overlay != nil {
+		// An intro would hold back the track the tail fades into, so the clip plays over it instead.
+		overlay.Intro, overlay.Length = false, 0
+	}
+	if err := c.playMedia(chatID, next.FilePath, false, 0, streamStart{fade: fade, overlay: overlay}); err != nil {
+		logger.Warn("[crossfade] Failed to crossfade in chat %d: %v", chatID, err)
+		return
+	}
+
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+	reply, err := c.bot.SendMessage(chatID, fmt.Sprintf(lang.GetString(langCode, "crossfade_next"), next.Name))
+	if err != nil {
+		logger.Warn("[crossfade] Failed to send message: %v", err)
+		return
+	}
+	c.showNowPlaying(chatID, next, reply)
+}
+
+// SetCrossfade stores the chat's crossfade length in seconds, 0 to turn crossfading off.
+// It applies from the next transition on.
+func (c *TelegramCalls) SetCrossfade(chatID int64, seconds int) (int, error) {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+
+	seconds = min(max(seconds, 0), MaxCrossfade)
+	if seconds == 0 {
+		dropPrefetch(chatID)
+	}
+	return seconds, db.Instance.SetCrossfade(ctx, chatID, seconds)
+}
@@ -0,0 +1,55 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package vc
+
+import (
+	"errors"
+	"fmt"
+
+	"ashokshau/tgmusic/src/core"
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+)
+
+// ApplyEffect turns on the named audio effect in a chat, or turns effects off for "off",
+// and restarts the current track at its current position.
+func (c *TelegramCalls) ApplyEffect(chatID int64, name string) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	if name != "off" {
+		effect, ok := core.GetEffectPreset(name)
+		if !ok {
+			return errors.New(lang.GetString(langCode, "effect_invalid"))
+		}
+		name = effect.Name
+	} else {
+		name = ""
+	}
+
+	playingSong := cache.ChatCache.GetPlayingTrack(chatID)
+	if playingSong == nil {
+		return errors.New(lang.GetString(langCode, "no_song_playing"))
+	}
+
+	return c.restartWith(chatID, playingSong, func(state *cache.PlaybackState) {
+		state.Effect = name
+	})
+}
+
+// EffectLine returns the now-playing line describing the chat's active effect, or an empty string if none is active.
+func EffectLine(langCode string, chatID int64) string {
+	effect, ok := core.GetEffectPreset(cache.ChatCache.GetPlayback(chatID).Effect)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf(lang.GetString(langCode, "now_playing_effect"), effect.Label)
+}
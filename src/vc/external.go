@@ -0,0 +1,223 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package vc
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"math"
+	"os/exec"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/vc/ntgcalls"
+	"ashokshau/tgmusic/src/vc/ubot"
+)
+
+// externalFrameInterval is the length of audio sent in one external frame.
+const externalFrameInterval = 10 * time.Millisecond
+
+// externalPlayer decodes a chat's current track in a Go-managed pipeline and sends the PCM frames to ntgcalls.
+// Pausing, muting and volume changes only change how frames are sent, so they take effect at once.
+// The engine handles audio only: video streams stay on ntgcalls' shell source, and a seek or an effect
+// change starts a new decoder at the new position.
+type externalPlayer struct {
+	chatID     int64
+	call       *ubot.Context
+	sampleRate int
+	channels   int
+
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	samples atomic.Uint64 // samples counts the samples per channel sent since the decoder started.
+	paused  atomic.Bool
+	muted   atomic.Bool
+	gain    atomic.Uint64 // gain holds the float64 bits of the volume factor.
+}
+
+// externalPlayers holds the running external player of every chat.
+var externalPlayers = struct {
+	sync.Mutex
+	byChat map[int64]*externalPlayer
+}{byChat: make(map[int64]*externalPlayer)}
+
+// useExternalEngine reports whether a stream is played by the external engine.
+// Video keeps using ntgcalls' shell source, since the external engine only handles audio.
+func useExternalEngine(video bool) bool {
+	return config.Conf.Engine == config.EngineExternal && !video
+}
+
+// getExternalPlayer returns the chat's running external player, or nil if it has none.
+func getExternalPlayer(chatID int64) *externalPlayer {
+	externalPlayers.Lock()
+	defer externalPlayers.Unlock()
+	return externalPlayers.byChat[chatID]
+}
+
+// stopExternalPlayer stops the chat's external player without reporting the end of its stream.
+func stopExternalPlayer(chatID int64) {
+	externalPlayers.Lock()
+	p := externalPlayers.byChat[chatID]
+	delete(externalPlayers.byChat, chatID)
+	externalPlayers.Unlock()
+
+	if p != nil {
+		p.cancel()
+		<-p.done
+	}
+}
+
+// playExternal starts the decoder for a stream and feeds its frames to the call.
+// The call's source is switched to external frames only when the chat has no external player yet,
+// so later tracks, seeks and effect changes restart the decoder alone.
+func (c *TelegramCalls) playExternal(chatID int64, call *ubot.Context, filePath string, state cache.PlaybackState, profile config.QualityProfile, gen uint64) error {
+	hadPlayer := getExternalPlayer(chatID) != nil
+	stopExternalPlayer(chatID)
+
+	if !hadPlayer {
+		err := call.Play(chatID, ntgcalls.MediaDescription{
+			Microphone: &ntgcalls.AudioDescription{
+				MediaSource:  ntgcalls.MediaSourceExternal,
+				SampleRate:   profile.SampleRate,
+				ChannelCount: profile.ChannelCount,
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// The volume is applied to the frames, so the decoder runs at full volume and a change needs no restart.
+	volume := state.Volume
+	state.Volume = 100
+	args := audioCommand(filePath, state, profile)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		cancel()
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return err
+	}
+
+	p := &externalPlayer{
+		chatID:     chatID,
+		call:       call,
+		sampleRate: int(profile.SampleRate),
+		channels:   int(profile.ChannelCount),
+		cancel:     cancel,
+		done:       make(chan struct{}),
+	}
+	p.setVolume(volume)
+
+	externalPlayers.Lock()
+	externalPlayers.byChat[chatID] = p
+	externalPlayers.Unlock()
+
+	go func() {
+		defer close(p.done)
+		ended := p.feed(ctx, stdout)
+		_ = cmd.Wait()
+		if ended {
+			externalPlayers.Lock()
+			if externalPlayers.byChat[chatID] == p {
+				delete(externalPlayers.byChat, chatID)
+			}
+			externalPlayers.Unlock()
+			go c.handleStreamEnd(chatID, gen)
+		}
+	}()
+	return nil
+}
+
+// feed sends the decoder's output to the call in real time until the output ends or ctx is cancelled.
+// It returns true if the stream ended on its own.
+func (p *externalPlayer) feed(ctx context.Context, stdout io.Reader) bool {
+	frame := make([]byte, p.sampleRate/100*p.channels*2)
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	// Each frame is due when the audio sent since start has played out, so a late frame is followed by
+	// quicker ones rather than the stream falling behind. Pausing restarts the clock.
+	var start time.Time
+	var sent uint64
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-timer.C:
+		}
+
+		if p.paused.Load() {
+			start = time.Time{}
+			timer.Reset(externalFrameInterval)
+			continue
+		}
+		if start.IsZero() {
+			start, sent = time.Now(), 0
+		}
+
+		if _, err := io.ReadFull(stdout, frame); err != nil {
+			return ctx.Err() == nil
+		}
+
+		p.applyGain(frame)
+		frameData := ntgcalls.FrameData{AbsoluteCaptureTimestampMs: time.Now().UnixMilli()}
+		if err := p.call.SendExternalFrame(p.chatID, ntgcalls.MicrophoneStream, frame, frameData); err != nil {
+			logger.Debug("[externalPlayer] Failed to send a frame to chat %d: %v", p.chatID, err)
+		}
+		n := uint64(len(frame) / (2 * p.channels))
+		p.samples.Add(n)
+		sent += n
+
+		wait := time.Until(start.Add(time.Duration(float64(sent) / float64(p.sampleRate) * float64(time.Second))))
+		if wait < -time.Second {
+			// The feed stalled for too long to catch up without a burst, so the clock starts over.
+			start = time.Time{}
+		}
+		timer.Reset(max(wait, 0))
+	}
+}
+
+// applyGain scales the signed 16-bit little-endian samples of a frame by the player's volume, or silences them when muted.
+func (p *externalPlayer) applyGain(frame []byte) {
+	if p.muted.Load() {
+		clear(frame)
+		return
+	}
+
+	gain := math.Float64frombits(p.gain.Load())
+	if gain == 1 {
+		return
+	}
+	for i := 0; i+1 < len(frame); i += 2 {
+		sample := float64(int16(binary.LittleEndian.Uint16(frame[i:]))) * gain
+		sample = max(min(sample, math.MaxInt16), math.MinInt16)
+		binary.LittleEndian.PutUint16(frame[i:], uint16(int16(sample)))
+	}
+}
+
+// setVolume sets the player's volume in percent.
+func (p *externalPlayer) setVolume(volume int) {
+	p.gain.Store(math.Float64bits(float64(volume) / 100))
+}
+
+// streamed returns how many seconds of audio the player has sent since its decoder started.
+func (p *externalPlayer) streamed() float64 {
+	return float64(p.samples.Load()) / float64(p.sampleRate)
+}
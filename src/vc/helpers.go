@@ -11,6 +11,7 @@ package vc
 import (
 	"context"
 	"fmt"
+	"html"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -21,7 +22,9 @@ import (
 
 	"ashokshau/tgmusic/src/config"
 	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
 	"ashokshau/tgmusic/src/core/dl"
+	"ashokshau/tgmusic/src/lang"
 	"ashokshau/tgmusic/src/vc/ntgcalls"
 
 	"github.com/amarnathcjd/gogram/telegram"
@@ -60,46 +63,131 @@ func getVideoDimensions(filePath string) (int, int) {
 
 var isURLRegex = regexp.MustCompile(`^https?://`)
 
-// getMediaDescription creates a media description for ntgcalls based on the provided file path, video status, and ffmpeg parameters.
-func getMediaDescription(filePath string, isVideo bool, ffmpegParameters string) ntgcalls.MediaDescription {
-	audioDescription := &ntgcalls.AudioDescription{
-		MediaSource:  ntgcalls.MediaSourceShell,
-		SampleRate:   96000,
-		ChannelCount: 2,
-	}
+// GetQualityProfile resolves the quality profile used in a chat.
+// The chat's own setting wins, then the global default set by the devs, then DEFAULT_QUALITY.
+func GetQualityProfile(chatID int64) config.QualityProfile {
+	ctx, cancel := db.Ctx()
+	defer cancel()
 
-	quotedPath := fmt.Sprintf("\"%s\"", filePath)
-	isURL := isURLRegex.MatchString(filePath)
+	if profile, ok := config.GetQualityProfile(db.Instance.GetQuality(ctx, chatID)); ok {
+		return profile
+	}
 
-	var audioCmd strings.Builder
-	audioCmd.WriteString("ffmpeg ")
-	if isURL {
-		audioCmd.WriteString("-reconnect 1 -reconnect_at_eof 1 -reconnect_streamed 1 -reconnect_delay_max 2 ")
+	if Calls != nil && Calls.bot != nil {
+		if profile, ok := config.GetQualityProfile(db.Instance.GetDefaultQuality(ctx, Calls.bot.Me().ID)); ok {
+			return profile
+		}
 	}
 
-	var seekFlags, filterFlags string
-	if ffmpegParameters != "" {
-		if strings.Contains(ffmpegParameters, "filter:") {
-			filterFlags = ffmpegParameters
+	profile, _ := config.GetQualityProfile(config.Conf.DefaultQuality)
+	return profile
+}
+
+// reconnectArgs makes ffmpeg reconnect to a URL input that drops, instead of ending the stream.
+var reconnectArgs = []string{"-reconnect", "1", "-reconnect_at_eof", "1", "-reconnect_streamed", "1", "-reconnect_delay_max", "2"}
+
+// shellSafeRegex matches arguments that need no quoting in a shell command.
+var shellSafeRegex = regexp.MustCompile(`^[A-Za-z0-9_./:=,+@%-]+$`)
+
+// shellJoin turns an argument list into a shell command, quoting every argument that needs it.
+// Paths, URLs and jingle sources come from users, so they must reach ffmpeg as one literal argument.
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if shellSafeRegex.MatchString(arg) {
+			quoted[i] = arg
 		} else {
-			seekFlags = ffmpegParameters
+			quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+	}
+	return strings.Join(quoted, " ")
+}
+
+// inputArgs returns the flags and the -i argument that open a file or URL as an ffmpeg input.
+func inputArgs(path string, flags ...string) []string {
+	var args []string
+	if isURLRegex.MatchString(path) {
+		args = append(args, reconnectArgs...)
+	}
+	args = append(args, flags...)
+	return append(args, "-i", path)
+}
+
+// audioFilterArgs returns the ffmpeg filter flags of an audio command whose inputs are, in order,
+// the previous track's tail when crossfading, the track itself and the overlay clip when there is one.
+func audioFilterArgs(state cache.PlaybackState, audioFilter string, profile config.QualityProfile) []string {
+	if state.Fade == nil && state.Overlay == nil {
+		if audioFilter == "" {
+			return nil
+		}
+		return []string{"-filter:a", audioFilter}
+	}
+
+	// The tail and the new track overlap for the whole tail, then the chat's filters apply to the mix.
+	chain := "[0:a]"
+	clip := "[1:a]"
+	if state.Fade != nil {
+		chain = fmt.Sprintf("[0:a][1:a]acrossfade=d=%d", state.Fade.End-state.Fade.Start)
+		clip = "[2:a]"
+		if audioFilter != "" {
+			chain += ","
 		}
 	}
+	if audioFilter == "" && state.Fade == nil {
+		audioFilter = "anull"
+	}
+	chain += audioFilter
 
-	if seekFlags != "" {
-		audioCmd.WriteString(seekFlags + " ")
+	overlay := state.Overlay
+	if overlay == nil {
+		return []string{"-filter_complex", chain}
+	}
+
+	// The clip skips the chat's filters, so it keeps its speed and an intro's length is known up front.
+	layout := "stereo"
+	if profile.ChannelCount == 1 {
+		layout = "mono"
+	}
+	format := fmt.Sprintf("aformat=sample_rates=%d:channel_layouts=%s", profile.SampleRate, layout)
+	graph := fmt.Sprintf("%s,%s[main];%s%s[clip];", chain, format, clip, format)
+	if overlay.Intro {
+		graph += "[clip][main]concat=n=2:v=0:a=1"
+	} else {
+		// The music is ducked while the clip plays over it.
+		graph += "[clip]asplit=2[sc][mix];[main][sc]sidechaincompress=threshold=0.02:ratio=10:attack=20:release=300[ducked];" +
+			"[ducked][mix]amix=inputs=2:duration=first:normalize=0"
 	}
+	return []string{"-filter_complex", graph}
+}
+
+// audioCommand returns the ffmpeg command, as an argument list, that decodes a stream's audio to s16le PCM
+// with the chat's playback state and quality profile applied.
+func audioCommand(filePath string, state cache.PlaybackState, profile config.QualityProfile) []string {
+	inputFlags, audioFilter, _ := buildFFmpegFlags(state)
 
-	audioCmd.WriteString("-i " + quotedPath + " ")
-	if filterFlags != "" {
-		audioCmd.WriteString(filterFlags + " ")
+	args := []string{"ffmpeg"}
+	if fade := state.Fade; fade != nil {
+		args = append(args, inputArgs(fade.FilePath, "-ss", fmt.Sprint(fade.Start), "-to", fmt.Sprint(fade.End))...)
+	}
+	args = append(args, inputArgs(filePath, inputFlags...)...)
+	if state.Overlay != nil {
+		args = append(args, "-i", state.Overlay.FilePath)
 	}
+	args = append(args, audioFilterArgs(state, audioFilter, profile)...)
+	return append(args,
+		"-f", "s16le", "-ac", fmt.Sprint(profile.ChannelCount), "-ar", fmt.Sprint(profile.SampleRate), "-v", "quiet", "pipe:1",
+	)
+}
 
-	audioCmd.WriteString(fmt.Sprintf("-f s16le -ac %d -ar %d -v quiet pipe:1",
-		audioDescription.ChannelCount,
-		audioDescription.SampleRate,
-	))
-	audioDescription.Input = audioCmd.String()
+// getMediaDescription creates a media description for ntgcalls based on the provided file path, video status,
+// the chat's playback state and quality profile.
+func getMediaDescription(filePath string, isVideo bool, state cache.PlaybackState, profile config.QualityProfile) ntgcalls.MediaDescription {
+	audioDescription := &ntgcalls.AudioDescription{
+		MediaSource:  ntgcalls.MediaSourceShell,
+		SampleRate:   profile.SampleRate,
+		ChannelCount: profile.ChannelCount,
+		Input:        shellJoin(audioCommand(filePath, state, profile)),
+	}
 
 	if !isVideo {
 		return ntgcalls.MediaDescription{
@@ -109,8 +197,8 @@ func getMediaDescription(filePath string, isVideo bool, ffmpegParameters string)
 
 	originalWidth, originalHeight := getVideoDimensions(filePath)
 
-	width := 1280
-	height := 720
+	width := profile.Width
+	height := profile.Height
 
 	if originalWidth > 0 && originalHeight > 0 {
 		ratio := float64(originalWidth) / float64(originalHeight)
@@ -137,32 +225,27 @@ func getMediaDescription(filePath string, isVideo bool, ffmpegParameters string)
 		MediaSource: ntgcalls.MediaSourceShell,
 		Width:       int16(width),
 		Height:      int16(height),
-		Fps:         30,
+		Fps:         profile.Fps,
 	}
 
-	var videoCmd strings.Builder
-	videoCmd.WriteString("ffmpeg ")
-
-	if isURL {
-		videoCmd.WriteString("-reconnect 1 -reconnect_at_eof 1 -reconnect_streamed 1 -reconnect_delay_max 2 ")
+	inputFlags, _, videoFilter := buildFFmpegFlags(state)
+	scale := fmt.Sprintf("scale=%d:%d", videoDescription.Width, videoDescription.Height)
+	if videoFilter != "" {
+		scale = videoFilter + "," + scale
 	}
 
-	if seekFlags != "" {
-		videoCmd.WriteString(seekFlags + " ")
-	}
+	videoArgs := append([]string{"ffmpeg"}, inputArgs(filePath, inputFlags...)...)
+	videoArgs = append(videoArgs,
+		"-f", "rawvideo", "-r", fmt.Sprint(videoDescription.Fps), "-pix_fmt", "yuv420p", "-vf", scale, "-v", "quiet", "pipe:1",
+	)
+	videoDescription.Input = shellJoin(videoArgs)
 
-	videoCmd.WriteString(fmt.Sprintf("-i %s ", quotedPath))
-	if filterFlags != "" {
-		videoCmd.WriteString(filterFlags + " ")
+	if state.Screen {
+		return ntgcalls.MediaDescription{
+			Microphone: audioDescription,
+			Screen:     videoDescription,
+		}
 	}
-
-	videoCmd.WriteString(fmt.Sprintf("-f rawvideo -r %d -pix_fmt yuv420p -vf scale=%d:%d -v quiet pipe:1",
-		videoDescription.Fps,
-		videoDescription.Width,
-		videoDescription.Height,
-	))
-	videoDescription.Input = videoCmd.String()
-
 	return ntgcalls.MediaDescription{
 		Microphone: audioDescription,
 		Camera:     videoDescription,
@@ -171,9 +254,9 @@ func getMediaDescription(filePath string, isVideo bool, ffmpegParameters string)
 
 var telegramMessageRegex = regexp.MustCompile(`t\.me/(\w+)/(\d+)`)
 
-// DownloadSong downloads a song using the provided cached track information.
+// DownloadSong downloads a song using the provided cached track information and the chat's quality profile.
 // It returns the file path, track information, and an error if the download fails.
-func DownloadSong(ctx context.Context, song *cache.CachedTrack, bot *telegram.Client) (string, *cache.TrackInfo, error) {
+func DownloadSong(ctx context.Context, chatID int64, song *cache.CachedTrack, bot *telegram.Client) (string, *cache.TrackInfo, error) {
 	if song.Platform == cache.Telegram {
 		file, err := telegram.ResolveBotFileID(song.TrackID)
 		if err != nil {
@@ -203,30 +286,66 @@ func DownloadSong(ctx context.Context, song *cache.CachedTrack, bot *telegram.Cl
 			return "", nil, err
 		}
 
-		filePath, err := wrapper.DownloadTrack(ctx, trackInfo, song.IsVideo)
-		if match := telegramMessageRegex.FindStringSubmatch(filePath); match != nil {
-			msg, err := dl.GetMessage(bot, filePath)
-			if err != nil {
-				return "", &trackInfo, fmt.Errorf("failed to get the message for %s: %w", trackInfo.Name, err)
+		profile := GetQualityProfile(chatID)
+		msgDuration := 0
+		download := func(ctx context.Context) (string, error) {
+			filePath, err := wrapper.DownloadTrack(ctx, trackInfo, song.IsVideo, profile)
+			if match := telegramMessageRegex.FindStringSubmatch(filePath); match != nil {
+				msg, err := dl.GetMessage(bot, filePath)
+				if err != nil {
+					return "", dl.NewError(dl.ErrNotFound, fmt.Errorf("failed to get the message for %s: %w", trackInfo.Name, err))
+				}
+
+				fileName := msg.File.Name
+				downloaded, err := msg.Download(&telegram.DownloadOptions{FileName: filepath.Join(config.Conf.DownloadsDir, fileName), Ctx: ctx})
+				if err != nil {
+					return "", fmt.Errorf("failed to download %s: %w", trackInfo.Name, err)
+				}
+
+				msgDuration = cache.GetFileDur(msg)
+				return downloaded, nil
 			}
 
-			fileName := msg.File.Name
-			download, err := msg.Download(&telegram.DownloadOptions{FileName: filepath.Join(config.Conf.DownloadsDir, fileName), Ctx: ctx})
-			if err != nil {
-				return "", &trackInfo, fmt.Errorf("failed to download %s: %w", trackInfo.Name, err)
-			}
+			return filePath, err
+		}
 
-			if trackInfo.Duration == 0 {
-				trackInfo.Duration = cache.GetFileDur(msg)
+		// Stream audio straight from the CDN while the download fills the cache.
+		if config.Conf.Progressive && !song.IsVideo && isURLRegex.MatchString(trackInfo.CdnURL) {
+			err := probeStreamURL(ctx, trackInfo.CdnURL)
+			if err == nil {
+				startBackgroundDownload(chatID, trackInfo.CdnURL, download)
+				return trackInfo.CdnURL, &trackInfo, nil
 			}
-
-			return download, &trackInfo, nil
+			logger.Warn("[DownloadSong] The CDN URL is not usable, downloading first: %v", err)
 		}
 
+		filePath, err := download(ctx)
+		if trackInfo.Duration == 0 {
+			trackInfo.Duration = msgDuration
+		}
 		return filePath, &trackInfo, err
 	}
 
-	return "", nil, fmt.Errorf("the provided song URL is invalid: %s", songUrl)
+	return "", nil, dl.NewError(dl.ErrNotFound, fmt.Errorf("the provided song URL is invalid: %s", songUrl))
+}
+
+// DownloadErrorText renders a localized message for a failed lookup or download.
+// The raw error is appended only when withDetails is true, which callers reserve for DEVS.
+func DownloadErrorText(langCode string, err error, withDetails bool) string {
+	text := lang.GetString(langCode, "dl_error_"+string(dl.KindOf(err)))
+	if withDetails && err != nil {
+		text += fmt.Sprintf(lang.GetString(langCode, "dl_error_details"), html.EscapeString(truncateText(err.Error(), 500)))
+	}
+	return text
+}
+
+// truncateText shortens s to at most maxLen runes.
+func truncateText(s string, maxLen int) string {
+	runes := []rune(s)
+	if len(runes) <= maxLen {
+		return s
+	}
+	return string(runes[:maxLen-3]) + "..."
 }
 
 // UpdateMembership updates the membership status of a user in a specific chat.
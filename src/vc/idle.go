@@ -0,0 +1,203 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package vc
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// Idle policies, applied once a chat's voice chat has had no listeners for its idle timeout.
+const (
+	IdleOff   = "off"
+	IdlePause = "pause" // IdlePause pauses, resumes when a listener joins and stops after a second timeout.
+	IdleStop  = "stop"
+	IdleLeave = "leave" // IdleLeave stops and makes the assistant leave the chat.
+)
+
+const (
+	// MinIdleTimeout and MaxIdleTimeout bound the idle timeout in minutes.
+	MinIdleTimeout = 1
+	MaxIdleTimeout = 60
+	// idleCheckInterval is how often active chats are checked for listeners between participant updates.
+	idleCheckInterval = 30 * time.Second
+)
+
+// idleState tracks a voice chat that has had no listeners since a given time.
+type idleState struct {
+	since  time.Time
+	paused bool // paused reports whether the idle policy paused the chat, so a listener joining resumes it.
+}
+
+// idleChats holds the idle state of every active chat without listeners.
+var idleChats = struct {
+	sync.Mutex
+	byChat map[int64]*idleState
+}{byChat: make(map[int64]*idleState)}
+
+// IsIdlePolicy reports whether policy is a valid idle policy.
+func IsIdlePolicy(policy string) bool {
+	switch policy {
+	case IdleOff, IdlePause, IdleStop, IdleLeave:
+		return true
+	}
+	return false
+}
+
+// watchIdle checks every active chat for listeners, catching the participant updates that were missed.
+func (c *TelegramCalls) watchIdle() {
+	ticker := time.NewTicker(idleCheckInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for _, chatID := range cache.ChatCache.GetActiveChats() {
+			c.checkIdle(chatID)
+		}
+	}
+}
+
+// checkIdle applies the chat's idle policy once its voice chat has had no listeners for its idle timeout,
+// and resumes a chat the policy paused as soon as a listener joins.
+func (c *TelegramCalls) checkIdle(chatID int64) {
+	// Private calls have no participants to watch.
+	if chatID > 0 {
+		return
+	}
+
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	policy := db.Instance.GetIdlePolicy(ctx, chatID)
+	if policy == IdleOff || !cache.ChatCache.IsActive(chatID) {
+		c.clearIdle(chatID, false)
+		return
+	}
+
+	listeners, err := c.countListeners(chatID)
+	if err != nil {
+		logger.Debug("[checkIdle] Failed to get the participants of chat %d: %v", chatID, err)
+		return
+	}
+	if listeners > 0 {
+		c.clearIdle(chatID, true)
+		return
+	}
+
+	idleChats.Lock()
+	state, ok := idleChats.byChat[chatID]
+	if !ok {
+		idleChats.byChat[chatID] = &idleState{since: time.Now()}
+		idleChats.Unlock()
+		return
+	}
+	idleFor, paused := time.Since(state.since), state.paused
+	idleChats.Unlock()
+
+	timeout := time.Duration(db.Instance.GetIdleTimeout(ctx, chatID)) * time.Minute
+	// A chat someone else paused is left paused, and the policy only stops it later.
+	playerState, _ := c.GetPlayerState(chatID)
+	switch {
+	case policy == IdlePause && !paused && playerState == PlayerPlaying && idleFor >= timeout:
+		ok, err := c.Pause(chatID)
+		if err != nil {
+			logger.Warn("[checkIdle] Failed to pause chat %d: %v", chatID, err)
+			return
+		}
+		idleChats.Lock()
+		state.paused = ok
+		idleChats.Unlock()
+		if ok {
+			c.sendIdle(chatID, "idle_paused", int(timeout.Minutes()))
+		}
+
+	case policy == IdlePause && idleFor >= 2*timeout, policy != IdlePause && idleFor >= timeout:
+		c.idleStop(chatID, policy == IdleLeave)
+	}
+}
+
+// countListeners returns how many participants other than the assistant are in the chat's voice chat.
+func (c *TelegramCalls) countListeners(chatID int64) (int, error) {
+	call, err := c.GetGroupAssistant(chatID)
+	if err != nil {
+		return 0, err
+	}
+	participants, err := call.GetParticipants(chatID)
+	if err != nil {
+		return 0, err
+	}
+
+	self := call.App.Me().ID
+	listeners := 0
+	for _, p := range participants {
+		if user, ok := p.Peer.(*tg.PeerUser); ok && user.UserID == self {
+			continue
+		}
+		listeners++
+	}
+	return listeners, nil
+}
+
+// clearIdle forgets that a chat has no listeners. If listenerJoined is set and the idle policy paused the chat,
+// the chat is resumed.
+func (c *TelegramCalls) clearIdle(chatID int64, listenerJoined bool) {
+	idleChats.Lock()
+	state, ok := idleChats.byChat[chatID]
+	delete(idleChats.byChat, chatID)
+	idleChats.Unlock()
+
+	if !ok || !state.paused || !listenerJoined {
+		return
+	}
+	if _, err := c.Resume(chatID); err != nil {
+		logger.Warn("[clearIdle] Failed to resume chat %d: %v", chatID, err)
+		return
+	}
+	c.sendIdle(chatID, "idle_resumed")
+}
+
+// idleStop stops a chat nobody listens to and, if leave is set, makes its assistant leave the chat.
+func (c *TelegramCalls) idleStop(chatID int64, leave bool) {
+	call, err := c.GetGroupAssistant(chatID)
+	if err != nil {
+		logger.Warn("[idleStop] Failed to get the assistant of chat %d: %v", chatID, err)
+		return
+	}
+	if err := c.Stop(chatID); err != nil {
+		logger.Warn("[idleStop] Failed to stop chat %d: %v", chatID, err)
+	}
+
+	if !leave {
+		c.sendIdle(chatID, "idle_stopped")
+		return
+	}
+	c.sendIdle(chatID, "idle_left")
+	if err := call.App.LeaveChannel(chatID); err != nil {
+		logger.Warn("[idleStop] The assistant failed to leave chat %d: %v", chatID, err)
+		return
+	}
+	c.UpdateMembership(chatID, call.App.Me().ID, tg.Left)
+}
+
+// sendIdle tells a chat what its idle policy did.
+func (c *TelegramCalls) sendIdle(chatID int64, key string, args ...any) {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+
+	text := lang.GetString(db.Instance.GetLang(ctx, chatID), key)
+	if len(args) > 0 {
+		text = fmt.Sprintf(text, args...)
+	}
+	_, _ = c.bot.SendMessage(chatID, text)
+}
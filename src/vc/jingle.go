@@ -0,0 +1,148 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package vc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/core/dl"
+	"ashokshau/tgmusic/src/lang"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+const (
+	// ttsTimeout bounds the command that renders an announcement.
+	ttsTimeout = 15 * time.Second
+	// jingleDownloadTimeout bounds the download of a jingle posted on Telegram.
+	jingleDownloadTimeout = time.Minute
+)
+
+// jingleFiles maps jingle sources posted on Telegram to their downloaded files, so each is downloaded once.
+var jingleFiles sync.Map
+
+// IsJingleSource reports whether source can be used as a jingle: a Telegram message link or an audio URL.
+func IsJingleSource(source string) bool {
+	return strings.HasPrefix(source, "https://t.me/") || isURLRegex.MatchString(source)
+}
+
+// trackIntro returns the clip a chat plays at the start of song: its spoken announcement if announcements are on
+// and a TTS command is configured, otherwise its jingle. It returns nil if the chat has neither.
+func (c *TelegramCalls) trackIntro(chatID int64, song *cache.CachedTrack) *cache.Overlay {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+
+	var path string
+	var err error
+	if config.Conf.TtsCommand != "" && db.Instance.GetAnnounce(ctx, chatID) {
+		langCode := db.Instance.GetLang(ctx, chatID)
+		text := fmt.Sprintf(lang.GetString(langCode, "announce_text"), song.Name, song.User)
+		path, err = speak(chatID, song.TrackID, text, langCode)
+	} else if source := db.Instance.GetJingle(ctx, chatID); source != "" {
+		path, err = c.resolveJingle(source)
+	}
+	if err != nil {
+		logger.Warn("[trackIntro] Failed to prepare the intro clip for chat %d: %v", chatID, err)
+		return nil
+	}
+	if path == "" {
+		return nil
+	}
+
+	// Video keeps its picture in step with the track, so its clip always plays over the music.
+	overlay := &cache.Overlay{FilePath: path, Intro: db.Instance.GetJingleIntro(ctx, chatID) && !song.IsVideo}
+	if overlay.Intro {
+		length := cache.GetFileDuration(path)
+		if length <= 0 {
+			overlay.Intro = false
+		}
+		overlay.Length = float64(length)
+	}
+	return overlay
+}
+
+// speak renders text with the configured TTS command and returns the file it wrote.
+// The command is split into arguments before the placeholders are filled, so the text never reaches a shell.
+func speak(chatID int64, trackID, text, langCode string) (string, error) {
+	fields := strings.Fields(config.Conf.TtsCommand)
+	if len(fields) == 0 {
+		return "", errors.New("no TTS command is configured")
+	}
+
+	output := filepath.Join(config.Conf.DownloadsDir, fmt.Sprintf("announce_%d_%s.wav", chatID, sanitizeFileName(trackID)))
+	replacer := strings.NewReplacer("{text}", text, "{output}", output, "{lang}", langCode)
+	args := make([]string, len(fields))
+	for i, field := range fields {
+		args[i] = replacer.Replace(field)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ttsTimeout)
+	defer cancel()
+	if out, err := exec.CommandContext(ctx, args[0], args[1:]...).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	if _, err := os.Stat(output); err != nil {
+		return "", fmt.Errorf("the TTS command did not write %s", output)
+	}
+	return output, nil
+}
+
+// resolveJingle returns a file or URL ffmpeg can read for a jingle source, downloading Telegram posts once.
+func (c *TelegramCalls) resolveJingle(source string) (string, error) {
+	if !strings.HasPrefix(source, "https://t.me/") {
+		return source, nil
+	}
+
+	if path, ok := jingleFiles.Load(source); ok {
+		if _, err := os.Stat(path.(string)); err == nil {
+			return path.(string), nil
+		}
+	}
+
+	msg, err := dl.GetMessage(c.bot, source)
+	if err != nil {
+		return "", err
+	}
+	if msg.File == nil {
+		return "", errors.New("the jingle message has no file")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), jingleDownloadTimeout)
+	defer cancel()
+	name := fmt.Sprintf("jingle_%s", sanitizeFileName(msg.File.Name))
+	path, err := msg.Download(&tg.DownloadOptions{FileName: filepath.Join(config.Conf.DownloadsDir, name), Ctx: ctx})
+	if err != nil {
+		return "", err
+	}
+
+	jingleFiles.Store(source, path)
+	return path, nil
+}
+
+// sanitizeFileName keeps the letters, digits, dots, dashes and underscores of a file name.
+func sanitizeFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, name)
+}
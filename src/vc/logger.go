@@ -10,9 +10,11 @@ package vc
 
 import (
 	"fmt"
+	"html"
 
 	"ashokshau/tgmusic/src/config"
 	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/dl"
 
 	tg "github.com/amarnathcjd/gogram/telegram"
 )
@@ -40,3 +42,46 @@ func sendLogger(client *tg.Client, chatID int64, song *cache.CachedTrack) {
 		logger.Warn("Failed to send the message: %v", err)
 	}
 }
+
+// sendDownloadError reports the raw cause of a failed download to the logger chat, which only the devs read.
+func sendDownloadError(client *tg.Client, chatID int64, song *cache.CachedTrack, err error) {
+	if config.Conf.LoggerId == 0 || song == nil || err == nil {
+		return
+	}
+
+	text := fmt.Sprintf(
+		"<b>A download failed</b> in <code>%d</code>\n\n‣ <b>Title:</b> <a href='%s'>%s</a>\n‣ <b>Kind:</b> %s\n‣ <b>Error:</b> <code>%s</code>",
+		chatID,
+		song.URL,
+		song.Name,
+		dl.KindOf(err),
+		html.EscapeString(truncateText(err.Error(), 1000)),
+	)
+
+	_, sendErr := client.SendMessage(config.Conf.LoggerId, text, &tg.SendOptions{LinkPreview: false})
+	if sendErr != nil {
+		logger.Warn("Failed to send the message: %v", sendErr)
+	}
+}
+
+// sendStreamRecovery reports to the logger chat that a stalled stream was restarted or skipped.
+func sendStreamRecovery(client *tg.Client, chatID int64, song *cache.CachedTrack, action string, position, attempt int) {
+	if config.Conf.LoggerId == 0 || song == nil {
+		return
+	}
+
+	text := fmt.Sprintf(
+		"<b>A stalled stream was recovered</b> in <code>%d</code>\n\n‣ <b>Title:</b> <a href='%s'>%s</a>\n‣ <b>Action:</b> %s\n‣ <b>Position:</b> %s\n‣ <b>Stall:</b> %d",
+		chatID,
+		song.URL,
+		song.Name,
+		action,
+		cache.SecToMin(position),
+		attempt,
+	)
+
+	_, err := client.SendMessage(config.Conf.LoggerId, text, &tg.SendOptions{LinkPreview: false})
+	if err != nil {
+		logger.Warn("Failed to send the message: %v", err)
+	}
+}
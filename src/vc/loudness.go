@@ -0,0 +1,158 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package vc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+)
+
+const (
+	// MinLoudnessTarget and MaxLoudnessTarget bound the target, in LUFS, accepted by ffmpeg's loudnorm filter.
+	MinLoudnessTarget = -70
+	MaxLoudnessTarget = -5
+	// DefaultLoudnessTarget is the target used when normalization is turned on without a level.
+	DefaultLoudnessTarget = -16
+
+	// loudnessAnalysisTimeout bounds the analysis pass of a single track.
+	loudnessAnalysisTimeout = 3 * time.Minute
+)
+
+// analyzing holds the IDs of the tracks whose loudness is being measured, so each track is analyzed once at a time.
+var analyzing sync.Map
+
+// loudnormFilter returns the loudnorm filter for a playback state.
+// With a measurement it runs the second, linear pass; without one it falls back to dynamic single-pass normalization.
+func loudnormFilter(state cache.PlaybackState) string {
+	filter := fmt.Sprintf("loudnorm=I=%d:TP=-1.5:LRA=11", state.LoudnessTarget)
+	if m := state.Loudness; m != nil {
+		filter += fmt.Sprintf(":measured_I=%.2f:measured_TP=%.2f:measured_LRA=%.2f:measured_thresh=%.2f:linear=true",
+			m.InputI, m.InputTP, m.InputLRA, m.InputThresh)
+	}
+	return filter
+}
+
+// trackLoudness returns the cached loudness of a track.
+// If the track has not been measured yet, it starts the analysis in the background and returns nil,
+// so this playback is normalized dynamically and later ones use the measurement.
+func trackLoudness(song *cache.CachedTrack, filePath string) *cache.Loudness {
+	if song == nil || song.TrackID == "" {
+		return nil
+	}
+
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	loudness, err := db.Instance.GetLoudness(ctx, song.TrackID)
+	if err != nil {
+		logger.Warn("[trackLoudness] Failed to get the loudness of %s: %v", song.TrackID, err)
+		return nil
+	}
+	if loudness != nil {
+		return loudness
+	}
+
+	// A stream URL would be downloaded a second time for the analysis, so only local files are measured.
+	if isURLRegex.MatchString(filePath) {
+		return nil
+	}
+	if _, running := analyzing.LoadOrStore(song.TrackID, struct{}{}); !running {
+		go analyzeLoudness(song.TrackID, filePath)
+	}
+	return nil
+}
+
+// analyzeLoudness runs the analysis pass of loudnorm on a file and stores the result for the track.
+func analyzeLoudness(trackID, filePath string) {
+	defer analyzing.Delete(trackID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), loudnessAnalysisTimeout)
+	defer cancel()
+	loudness, err := measureLoudness(ctx, filePath)
+	if err != nil {
+		logger.Warn("[analyzeLoudness] Failed to analyze %s: %v", trackID, err)
+		return
+	}
+
+	dbCtx, dbCancel := db.Ctx()
+	defer dbCancel()
+	if err := db.Instance.SetLoudness(dbCtx, trackID, loudness); err != nil {
+		logger.Warn("[analyzeLoudness] Failed to save the loudness of %s: %v", trackID, err)
+	}
+}
+
+// measureLoudness runs ffmpeg's loudnorm filter in analysis mode and parses the JSON report it prints.
+func measureLoudness(ctx context.Context, filePath string) (cache.Loudness, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-hide_banner", "-nostats", "-i", filePath,
+		"-vn", "-af", "loudnorm=print_format=json", "-f", "null", "-")
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return cache.Loudness{}, fmt.Errorf("ffmpeg failed: %w", err)
+	}
+
+	// The report is the last JSON object ffmpeg prints.
+	output := stderr.Bytes()
+	start := bytes.LastIndexByte(output, '{')
+	end := bytes.LastIndexByte(output, '}')
+	if start < 0 || end < start {
+		return cache.Loudness{}, errors.New("no loudnorm report in the ffmpeg output")
+	}
+
+	var report map[string]string
+	if err := json.Unmarshal(output[start:end+1], &report); err != nil {
+		return cache.Loudness{}, fmt.Errorf("invalid loudnorm report: %w", err)
+	}
+
+	values := make([]float64, 4)
+	for i, key := range []string{"input_i", "input_tp", "input_lra", "input_thresh"} {
+		value, err := strconv.ParseFloat(report[key], 64)
+		// Silent tracks measure as -inf, which the linear pass cannot use.
+		if err != nil || math.IsInf(value, 0) || math.IsNaN(value) {
+			return cache.Loudness{}, fmt.Errorf("invalid %s value %q", key, report[key])
+		}
+		values[i] = value
+	}
+
+	return cache.Loudness{InputI: values[0], InputTP: values[1], InputLRA: values[2], InputThresh: values[3]}, nil
+}
+
+// SetLoudnessTarget stores the chat's normalization target, 0 to turn normalization off,
+// and restarts the current track at its current position with it.
+func (c *TelegramCalls) SetLoudnessTarget(chatID int64, target int) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	if target != 0 && (target < MinLoudnessTarget || target > MaxLoudnessTarget) {
+		return errors.New(lang.GetString(langCode, "normalize_invalid"))
+	}
+	if err := db.Instance.SetLoudnessTarget(ctx, chatID, target); err != nil {
+		return err
+	}
+
+	playingSong := cache.ChatCache.GetPlayingTrack(chatID)
+	if playingSong == nil {
+		return nil
+	}
+
+	// PlayMedia reads the target from the database, so the state needs no update of its own.
+	return c.restartWith(chatID, playingSong, func(*cache.PlaybackState) {})
+}
@@ -0,0 +1,127 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package vc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ashokshau/tgmusic/src/core"
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+)
+
+// buildFFmpegFlags turns a playback state into ffmpeg input flags and audio and video filter chains.
+// Filters are returned without the -filter flag so callers can combine them with their own.
+func buildFFmpegFlags(state cache.PlaybackState) (inputFlags []string, audioFilter, videoFilter string) {
+	if start := max(state.Offset, state.TrimStart); start > 0 {
+		inputFlags = append(inputFlags, "-ss", fmt.Sprint(start))
+	}
+	if state.TrimEnd > 0 {
+		inputFlags = append(inputFlags, "-to", fmt.Sprint(state.TrimEnd))
+	}
+
+	var audio []string
+	// The measurement is of the untouched track, so normalization runs before anything changes it.
+	if state.LoudnessTarget != 0 {
+		audio = append(audio, loudnormFilter(state))
+	}
+
+	effect, hasEffect := core.GetEffectPreset(state.Effect)
+	if hasEffect {
+		audio = append(audio, effect.Filter)
+	}
+
+	if state.Speed > 0 && state.Speed != 1 {
+		// atempo only accepts factors between 0.5 and 2, so larger changes are chained.
+		remaining := state.Speed
+		for remaining > 2.0 {
+			audio = append(audio, "atempo=2.0")
+			remaining /= 2.0
+		}
+		for remaining < 0.5 {
+			audio = append(audio, "atempo=0.5")
+			remaining /= 0.5
+		}
+		audio = append(audio, fmt.Sprintf("atempo=%f", remaining))
+	}
+
+	if state.Volume != 100 {
+		audio = append(audio, fmt.Sprintf("volume=%.2f", float64(state.Volume)/100))
+	}
+
+	if !state.FadeUntil.IsZero() {
+		audio = append(audio, fmt.Sprintf("afade=t=out:st=0:d=%d", max(int(time.Until(state.FadeUntil).Seconds()), 1)))
+	}
+
+	if tempo := streamTempo(state); tempo != 1 {
+		videoFilter = fmt.Sprintf("setpts=PTS/%f", tempo)
+	}
+
+	return inputFlags, strings.Join(audio, ","), videoFilter
+}
+
+// streamTempo returns how many seconds of the track one second of the stream covers.
+func streamTempo(state cache.PlaybackState) float64 {
+	tempo := 1.0
+	if state.Speed > 0 {
+		tempo = state.Speed
+	}
+	if effect, ok := core.GetEffectPreset(state.Effect); ok && effect.Tempo > 0 {
+		tempo *= effect.Tempo
+	}
+	return tempo
+}
+
+// trackPosition converts the seconds streamed by the current ffmpeg process into a track position.
+// The seconds an intro clip plays before the track do not count.
+func trackPosition(state cache.PlaybackState, streamed float64) int {
+	return max(state.Offset, state.TrimStart) + int(max(streamed-state.Lead, 0)*streamTempo(state))
+}
+
+const (
+	// MaxVolume is the highest volume, in percent, a chat can set.
+	MaxVolume = 200
+	// VolumeStep is how much the volume buttons change the volume, in percent.
+	VolumeStep = 10
+)
+
+// SetVolume stores the chat's volume and restarts the current track at its current position with it.
+// The volume is clamped to 0-MaxVolume and applies to every later track. It returns the volume that was set.
+func (c *TelegramCalls) SetVolume(chatID int64, volume int) (int, error) {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	volume = min(max(volume, 0), MaxVolume)
+	if err := db.Instance.SetVolume(ctx, chatID, volume); err != nil {
+		return 0, err
+	}
+
+	playingSong := cache.ChatCache.GetPlayingTrack(chatID)
+	if playingSong == nil {
+		return volume, errors.New(lang.GetString(langCode, "no_song_playing"))
+	}
+
+	// The external engine scales its frames itself, so the change needs no restart.
+	if p := getExternalPlayer(chatID); p != nil {
+		p.setVolume(volume)
+		cache.ChatCache.UpdatePlayback(chatID, func(state *cache.PlaybackState) {
+			state.Volume = volume
+		})
+		return volume, nil
+	}
+
+	return volume, c.restartWith(chatID, playingSong, func(state *cache.PlaybackState) {
+		state.Volume = volume
+	})
+}
@@ -0,0 +1,163 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package vc
+
+import (
+	"sync"
+
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/vc/ubot"
+)
+
+// PlayerState is the stage a chat's player is in.
+type PlayerState int
+
+const (
+	PlayerIdle     PlayerState = iota // PlayerIdle has nothing to play.
+	PlayerLoading                     // PlayerLoading is downloading a track or starting its stream.
+	PlayerPlaying                     // PlayerPlaying is streaming a track.
+	PlayerPaused                      // PlayerPaused holds a track's stream.
+	PlayerStopping                    // PlayerStopping is leaving the call and clearing the queue.
+)
+
+// String returns the name of the state.
+func (s PlayerState) String() string {
+	switch s {
+	case PlayerLoading:
+		return "loading"
+	case PlayerPlaying:
+		return "playing"
+	case PlayerPaused:
+		return "paused"
+	case PlayerStopping:
+		return "stopping"
+	default:
+		return "idle"
+	}
+}
+
+// player runs the commands of one chat one at a time and records where its playback is.
+// cmd is held for a whole command, so a skip and a stream end never both move the queue on.
+// Only a track's download and intro are run without it, and playSong checks the chat is unchanged afterwards.
+// mu guards the fields, so the state can be read while a command runs.
+type player struct {
+	cmd sync.Mutex
+
+	mu    sync.Mutex
+	state PlayerState
+	muted bool
+	// generation counts the streams the chat has started. A stream end reports the generation it ended,
+	// which is stale once a newer stream has started.
+	generation uint64
+}
+
+var players = struct {
+	sync.Mutex
+	byChat map[int64]*player
+}{byChat: make(map[int64]*player)}
+
+// getPlayer returns the player of a chat, creating an idle one if the chat has none.
+func getPlayer(chatID int64) *player {
+	players.Lock()
+	defer players.Unlock()
+
+	p, ok := players.byChat[chatID]
+	if !ok {
+		p = &player{}
+		players.byChat[chatID] = p
+	}
+	return p
+}
+
+// lockPlayer waits for the chat's running command to finish and returns the function that ends the caller's.
+func lockPlayer(chatID int64) func() {
+	p := getPlayer(chatID)
+	p.cmd.Lock()
+	return p.cmd.Unlock
+}
+
+// setState moves the player to state.
+func (p *player) setState(state PlayerState) {
+	p.mu.Lock()
+	p.state = state
+	p.mu.Unlock()
+}
+
+// setMuted records whether the player's playback is muted.
+func (p *player) setMuted(muted bool) {
+	p.mu.Lock()
+	p.muted = muted
+	p.mu.Unlock()
+}
+
+// getState returns the player's state and whether it is muted.
+func (p *player) getState() (PlayerState, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.state, p.muted
+}
+
+// currentGeneration returns the generation of the player's latest stream.
+func (p *player) currentGeneration() uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.generation
+}
+
+// nextGeneration records that a new stream is starting and returns its generation.
+// It returns whether the player was paused, since a restart of a paused track stays paused.
+func (p *player) nextGeneration() (uint64, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.generation++
+	wasPaused := p.state == PlayerPaused
+	p.state = PlayerLoading
+	return p.generation, wasPaused
+}
+
+// GetPlayerState returns the stage of a chat's player and whether its playback is muted.
+// A chat whose queue has been cleared is idle whatever its player last recorded.
+func (c *TelegramCalls) GetPlayerState(chatID int64) (PlayerState, bool) {
+	if !cache.ChatCache.IsActive(chatID) {
+		return PlayerIdle, false
+	}
+	return getPlayer(chatID).getState()
+}
+
+// streamStarted moves the player of a chat whose new stream has started to playing.
+// The stream is paused again if the player was paused and muted if the player is muted,
+// since neither engine carries those over to a new stream.
+func (c *TelegramCalls) streamStarted(chatID int64, call *ubot.Context, wasPaused bool) {
+	p := getPlayer(chatID)
+	state, muted := p.getState()
+	if state != PlayerLoading {
+		return
+	}
+
+	if muted {
+		if _, err := c.mute(chatID, call); err != nil {
+			logger.Warn("[streamStarted] Failed to keep chat %d muted: %v", chatID, err)
+		}
+	}
+	if wasPaused {
+		if _, err := c.pause(chatID, call); err == nil {
+			p.setState(PlayerPaused)
+			return
+		}
+	}
+	p.setState(PlayerPlaying)
+}
+
+// resetPlayer returns a chat's player to idle once its playback has ended for good.
+func resetPlayer(chatID int64) {
+	p := getPlayer(chatID)
+	p.mu.Lock()
+	p.state, p.muted = PlayerIdle, false
+	p.mu.Unlock()
+}
@@ -0,0 +1,180 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package vc
+
+import (
+	"fmt"
+	"sync"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc/ntgcalls"
+	"ashokshau/tgmusic/src/vc/ubot"
+)
+
+// privateCalls holds the name of the assistant each user in a private call is talking to.
+// The caller's queue lives under their user ID, which is also the bot's DM with them.
+// The name is resolved through the pool on every use, so a restarted assistant is never reached through its old instance.
+var privateCalls = struct {
+	sync.Mutex
+	byUser map[int64]string
+}{byUser: make(map[int64]string)}
+
+// privateAssistantName returns the name of the assistant the user is in a private call with,
+// or an empty string if they are in none.
+func privateAssistantName(userID int64) string {
+	privateCalls.Lock()
+	defer privateCalls.Unlock()
+	return privateCalls.byUser[userID]
+}
+
+// HasPrivateCall reports whether the user is in a private call with one of the assistants.
+func HasPrivateCall(userID int64) bool {
+	return privateAssistantName(userID) != ""
+}
+
+// PrivateCallMode returns how the assistants handle private calls: the devs' choice if they made one,
+// otherwise config.Conf.PrivateCalls.
+func (c *TelegramCalls) PrivateCallMode() string {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	if mode := db.Instance.GetPrivateCalls(ctx, c.bot.Me().ID); mode != "" {
+		return mode
+	}
+	return config.Conf.PrivateCalls
+}
+
+// handleIncomingCall declines a private call if the devs turned them off. Otherwise it ties the caller's queue
+// to the assistant they called, tells them how to control it from the bot's DM and, in answer mode, picks up.
+func (c *TelegramCalls) handleIncomingCall(call *ubot.Context, userID int64) {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, userID)
+
+	mode := c.PrivateCallMode()
+	if mode == config.PrivateCallsOff {
+		_, _ = call.App.SendMessage(userID, lang.GetString(langCode, "incoming_call_disabled"))
+		if err := call.Stop(userID); err != nil {
+			logger.Debug("[handleIncomingCall] Failed to decline the call of %d: %v", userID, err)
+		}
+		return
+	}
+
+	name := c.assistantName(call)
+	if name == "" {
+		return
+	}
+	privateCalls.Lock()
+	privateCalls.byUser[userID] = name
+	privateCalls.Unlock()
+
+	key := "incoming_call_answer"
+	if mode == config.PrivateCallsQueue {
+		key = "incoming_call_queue"
+	}
+	_, _ = call.App.SendMessage(userID, fmt.Sprintf(lang.GetString(langCode, key), c.bot.Me().Username))
+
+	if mode == config.PrivateCallsAnswer {
+		if err := c.answerPrivateCall(userID); err != nil {
+			logger.Warn("[handleIncomingCall] Failed to answer the call of %d: %v", userID, err)
+		}
+	}
+}
+
+// answerPrivateCall picks up the user's call with config.Conf.CallGreeting, or with silence if it is not set.
+// The greeting is not queued, so a track the caller queues meanwhile replaces it.
+func (c *TelegramCalls) answerPrivateCall(userID int64) error {
+	if config.Conf.CallGreeting == "" {
+		defer lockPlayer(userID)()
+		if !HasPrivateCall(userID) {
+			return nil
+		}
+		call, err := c.GetGroupAssistant(userID)
+		if err != nil {
+			return err
+		}
+		return call.Play(userID, ntgcalls.MediaDescription{})
+	}
+
+	path, err := c.resolveJingle(config.Conf.CallGreeting)
+	if err != nil {
+		return err
+	}
+	return c.PlayMedia(userID, path, false, 0)
+}
+
+// endPrivateCall clears the queue of a user whose private call with call has ended.
+func (c *TelegramCalls) endPrivateCall(call *ubot.Context, userID int64) {
+	name := c.assistantName(call)
+	if name == "" || privateAssistantName(userID) != name {
+		return
+	}
+
+	defer lockPlayer(userID)()
+	c.forgetPrivateCall(userID, name)
+}
+
+// forgetPrivateCall clears the queue of a user whose private call with the named assistant is over.
+// The caller runs a command of the user's player.
+func (c *TelegramCalls) forgetPrivateCall(userID int64, name string) {
+	privateCalls.Lock()
+	if privateCalls.byUser[userID] != name {
+		privateCalls.Unlock()
+		return
+	}
+	delete(privateCalls.byUser, userID)
+	privateCalls.Unlock()
+
+	c.clearPlayback(userID)
+	resetPlayer(userID)
+	logger.Info("[forgetPrivateCall] The private call of %d with %s ended", userID, name)
+}
+
+// endPrivateCalls forgets every private call with the named assistant, whose calls were dropped
+// when its client was restarted. The users call again when they want more music.
+func (c *TelegramCalls) endPrivateCalls(name string) {
+	var users []int64
+	privateCalls.Lock()
+	for userID, assistant := range privateCalls.byUser {
+		if assistant == name {
+			users = append(users, userID)
+		}
+	}
+	privateCalls.Unlock()
+
+	for _, userID := range users {
+		unlock := lockPlayer(userID)
+		c.forgetPrivateCall(userID, name)
+		unlock()
+	}
+}
+
+// hangUpPrivateCall ends a private call whose connection failed. A private call cannot be rejoined,
+// and the user calls again when they want more music.
+func (c *TelegramCalls) hangUpPrivateCall(userID int64) {
+	name := privateAssistantName(userID)
+	if name == "" {
+		return
+	}
+	if call, err := c.assistantByName(name); err == nil {
+		if err := call.Stop(userID); err != nil {
+			logger.Debug("[hangUpPrivateCall] Failed to hang up the call of %d: %v", userID, err)
+		}
+	}
+
+	defer lockPlayer(userID)()
+	c.forgetPrivateCall(userID, name)
+}
+
+// greetingEnded reports whether a finished stream was the greeting of a private call rather than a queued track.
+func greetingEnded(chatID int64) bool {
+	return HasPrivateCall(chatID) && !cache.ChatCache.IsActive(chatID)
+}
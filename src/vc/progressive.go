@@ -0,0 +1,229 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package vc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/core/cache"
+)
+
+const (
+	// backgroundDownloadTimeout bounds the background download that fills the cache during progressive playback.
+	backgroundDownloadTimeout = 10 * time.Minute
+	// progressiveRecoverWait is how long a failed progressive stream waits for the local copy before giving up.
+	progressiveRecoverWait = 2 * time.Minute
+	// progressiveEndSlack is how close to the end a stream may stop and still count as finished.
+	progressiveEndSlack = 5
+)
+
+// backgroundDownload is a download running while its track is streamed from the CDN URL.
+type backgroundDownload struct {
+	remoteURL string
+	done      chan struct{}
+	path      string
+	err       error
+}
+
+// finished reports whether the download has ended, successfully or not.
+func (d *backgroundDownload) finished() bool {
+	select {
+	case <-d.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// progressiveKey identifies a background download by the chat that started it and its CDN URL,
+// so two chats streaming the same URL each keep their own download.
+type progressiveKey struct {
+	chatID    int64
+	remoteURL string
+}
+
+// progressiveState tracks the running background downloads and the ones being streamed by chat.
+type progressiveState struct {
+	mu      sync.Mutex
+	running map[progressiveKey]*backgroundDownload
+	byChat  map[int64]*backgroundDownload
+}
+
+var progressive = &progressiveState{
+	running: make(map[progressiveKey]*backgroundDownload),
+	byChat:  make(map[int64]*backgroundDownload),
+}
+
+// probeStreamURL checks that a CDN URL still answers before ffmpeg is pointed at it.
+func probeStreamURL(ctx context.Context, url string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Range", "bytes=0-0")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		return fmt.Errorf("the CDN URL answered with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// startBackgroundDownload runs download in the background and points the chat's queue at the local file once it finishes.
+func startBackgroundDownload(chatID int64, remoteURL string, download func(ctx context.Context) (string, error)) {
+	d := &backgroundDownload{remoteURL: remoteURL, done: make(chan struct{})}
+	key := progressiveKey{chatID: chatID, remoteURL: remoteURL}
+
+	progressive.mu.Lock()
+	progressive.running[key] = d
+	progressive.mu.Unlock()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), backgroundDownloadTimeout)
+		defer cancel()
+
+		d.path, d.err = download(ctx)
+		if d.err == nil && d.path == "" {
+			d.err = errors.New("the background download returned an empty path")
+		}
+		close(d.done)
+
+		progressive.mu.Lock()
+		if progressive.running[key] == d {
+			delete(progressive.running, key)
+		}
+		progressive.mu.Unlock()
+
+		if d.err != nil {
+			logger.Warn("[Progressive] Background download failed in chat %d: %v", chatID, d.err)
+			return
+		}
+		cache.ChatCache.ReplaceFilePath(chatID, remoteURL, d.path)
+	}()
+}
+
+// resolveProgressive is called before a file is played in a chat.
+// It returns the local copy if the background download of a CDN URL already finished,
+// and remembers the download when the chat starts streaming the URL itself.
+func resolveProgressive(chatID int64, filePath string) string {
+	progressive.mu.Lock()
+	defer progressive.mu.Unlock()
+
+	if d, ok := progressive.running[progressiveKey{chatID: chatID, remoteURL: filePath}]; ok {
+		progressive.byChat[chatID] = d
+		return filePath
+	}
+
+	if d, ok := progressive.byChat[chatID]; ok && d.remoteURL == filePath {
+		select {
+		case <-d.done:
+			if d.err == nil {
+				delete(progressive.byChat, chatID)
+				cache.ChatCache.ReplaceFilePath(chatID, filePath, d.path)
+				return d.path
+			}
+		default:
+		}
+		return filePath
+	}
+
+	delete(progressive.byChat, chatID)
+	return filePath
+}
+
+// recoverProgressive handles the end of stream gen while a chat was playing a CDN URL.
+// If the stream stopped early, for example because the URL expired, the chat resumes from the last position
+// on the local copy. A copy that is still downloading is waited for in the background, so the chat's player
+// is free for /skip and /stop meanwhile. It returns true if the chat was resumed or is waiting for its copy.
+func (c *TelegramCalls) recoverProgressive(chatID int64, gen uint64) bool {
+	progressive.mu.Lock()
+	d, ok := progressive.byChat[chatID]
+	delete(progressive.byChat, chatID)
+	progressive.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	song := cache.ChatCache.GetPlayingTrack(chatID)
+	if song == nil {
+		return false
+	}
+
+	played, err := c.PlayedTime(chatID)
+	if err != nil || song.Duration <= 0 || int(played) >= song.Duration-progressiveEndSlack {
+		return false
+	}
+
+	if d.finished() {
+		return c.resumeProgressive(chatID, song, d, int(played))
+	}
+
+	logger.Info("[Progressive] The stream in chat %d stopped at %ds of %ds, waiting for the local copy.", chatID, played, song.Duration)
+	getPlayer(chatID).setState(PlayerLoading)
+	go c.awaitProgressive(chatID, gen, song, d, int(played))
+	return true
+}
+
+// awaitProgressive waits for the local copy of song and resumes the chat from played,
+// unless a command moved the chat on meanwhile. Without a copy the chat moves on to its next track.
+func (c *TelegramCalls) awaitProgressive(chatID int64, gen uint64, song *cache.CachedTrack, d *backgroundDownload, played int) {
+	select {
+	case <-d.done:
+	case <-time.After(progressiveRecoverWait):
+	}
+
+	p := getPlayer(chatID)
+	p.cmd.Lock()
+	defer p.cmd.Unlock()
+	if p.currentGeneration() != gen || cache.ChatCache.GetPlayingTrack(chatID) != song {
+		return
+	}
+
+	if !c.resumeProgressive(chatID, song, d, played) {
+		c.advance(chatID)
+	}
+}
+
+// resumeProgressive plays song from the local copy at played and returns true.
+// Without a copy the expired CDN URL cannot be played again, so the track's loop and repeated section
+// are dropped and it returns false for the caller to move the chat on.
+func (c *TelegramCalls) resumeProgressive(chatID int64, song *cache.CachedTrack, d *backgroundDownload, played int) bool {
+	switch {
+	case !d.finished():
+		logger.Warn("[Progressive] The local copy for chat %d is not ready, skipping.", chatID)
+	case d.err != nil:
+		logger.Warn("[Progressive] The local copy for chat %d failed, skipping: %v", chatID, d.err)
+	default:
+		cache.ChatCache.ReplaceFilePath(chatID, d.remoteURL, d.path)
+		err := c.playMedia(chatID, d.path, song.IsVideo, played, streamStart{})
+		if err == nil {
+			return true
+		}
+		logger.Warn("[Progressive] Failed to resume chat %d from the local copy: %v", chatID, err)
+	}
+
+	cache.ChatCache.SetLoopCount(chatID, 0)
+	cache.ChatCache.UpdatePlayback(chatID, clearRepeat)
+	return false
+}
@@ -0,0 +1,176 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package vc
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc/ntgcalls"
+	"ashokshau/tgmusic/src/vc/ubot"
+)
+
+const (
+	// rejoinBaseDelay is the wait before the first rejoin; each later attempt waits twice as long.
+	rejoinBaseDelay = time.Second
+	// rejoinMaxDelay caps the wait between rejoin attempts.
+	rejoinMaxDelay = 30 * time.Second
+	// maxRejoinAttempts is how many times a dropped call is rejoined before the chat is stopped.
+	maxRejoinAttempts = 8
+)
+
+// rejoins holds the chats whose dropped call is being rejoined.
+var rejoins = struct {
+	sync.Mutex
+	byChat map[int64]bool
+}{byChat: make(map[int64]bool)}
+
+// handleConnectionChange records the connection state of a chat's call and, when the call of an active chat drops,
+// rejoins it in the background.
+func (c *TelegramCalls) handleConnectionChange(call *ubot.Context, chatID int64, info ntgcalls.NetworkInfo) {
+	if info.Kind != ntgcalls.NormalConnection {
+		return
+	}
+	noteConnection(chatID, info.State)
+
+	switch info.State {
+	case ntgcalls.Failed, ntgcalls.Timeout, ntgcalls.Closed:
+	default:
+		return
+	}
+	if HasPrivateCall(chatID) {
+		go c.hangUpPrivateCall(chatID)
+		return
+	}
+	if !cache.ChatCache.IsActive(chatID) {
+		return
+	}
+
+	rejoins.Lock()
+	if rejoins.byChat[chatID] {
+		rejoins.Unlock()
+		return
+	}
+	rejoins.byChat[chatID] = true
+	rejoins.Unlock()
+
+	position := dropPosition(chatID, call)
+	logger.Warn("[handleConnectionChange] The call in chat %d dropped (state=%v) at %ds; rejoining", chatID, info.State, position)
+	go c.rejoin(chatID, call, position)
+}
+
+// dropPosition returns the track position a dropped call reached, using the watchdog's last reading
+// when the call can no longer report its time.
+func dropPosition(chatID int64, call *ubot.Context) int {
+	state := cache.ChatCache.GetPlayback(chatID)
+	if p := getExternalPlayer(chatID); p != nil {
+		return trackPosition(state, p.streamed())
+	}
+	if streamed, err := call.Time(chatID, 0); err == nil && streamed > 0 {
+		return trackPosition(state, float64(streamed))
+	}
+	_, streamed := lastProgress(chatID)
+	return trackPosition(state, streamed)
+}
+
+// rejoin rejoins a chat's dropped call at position with capped exponential backoff. It stops when the chat stops,
+// moves to another assistant or reconnects by itself, and stops the chat with a notice if the voice chat has ended
+// or every attempt fails.
+func (c *TelegramCalls) rejoin(chatID int64, dropped *ubot.Context, position int) {
+	defer func() {
+		rejoins.Lock()
+		delete(rejoins.byChat, chatID)
+		rejoins.Unlock()
+	}()
+
+	delay := rejoinBaseDelay
+	for attempt := 1; attempt <= maxRejoinAttempts; attempt++ {
+		time.Sleep(delay)
+		delay = min(delay*2, rejoinMaxDelay)
+
+		song := cache.ChatCache.GetPlayingTrack(chatID)
+		if !cache.ChatCache.IsActive(chatID) || song == nil {
+			return
+		}
+		if network, _ := lastProgress(chatID); network == ntgcalls.Connected {
+			return
+		}
+		call, err := c.GetGroupAssistant(chatID)
+		if err != nil {
+			logger.Warn("[rejoin] Failed to get the assistant of chat %d: %v", chatID, err)
+			continue
+		}
+		if call != dropped {
+			// The chat moved to another assistant, which resumes its stream.
+			return
+		}
+
+		rejoined, err := c.rejoinCall(chatID, call, song, position)
+		if err == nil {
+			if rejoined {
+				logger.Info("[rejoin] Rejoined the call in chat %d at %ds (attempt %d)", chatID, position, attempt)
+			} else {
+				logger.Info("[rejoin] Chat %d moved to another track before it was rejoined", chatID)
+			}
+			return
+		}
+		if voiceChatEnded(err) {
+			c.abandonCall(chatID, "reconnect_vc_ended")
+			return
+		}
+		logger.Warn("[rejoin] Attempt %d to rejoin chat %d failed: %v", attempt, chatID, err)
+	}
+
+	c.abandonCall(chatID, "reconnect_failed")
+}
+
+// rejoinCall leaves a chat's dropped call and joins it again with the same stream, started from position.
+// Unlike PlayMedia, a failure leaves the queue in place so the next attempt can use it.
+// It returns false without an error if there was nothing to rejoin, since the chat moved to another track meanwhile.
+func (c *TelegramCalls) rejoinCall(chatID int64, call *ubot.Context, song *cache.CachedTrack, position int) (bool, error) {
+	defer lockPlayer(chatID)()
+	// A command that moved the chat to another track meanwhile has started a stream of its own.
+	if playing := cache.ChatCache.GetPlayingTrack(chatID); playing == nil || playing.TrackID != song.TrackID {
+		return false, nil
+	}
+
+	stopExternalPlayer(chatID)
+	_ = call.Stop(chatID)
+
+	cache.ChatCache.UpdatePlayback(chatID, func(state *cache.PlaybackState) {
+		state.Offset, state.Lead = position, 0
+	})
+	state := cache.ChatCache.GetPlayback(chatID)
+	if err := c.startStream(chatID, call, resolveProgressive(chatID, song.FilePath), song.IsVideo, state); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// voiceChatEnded reports whether a join failed because the chat's voice chat no longer exists.
+func voiceChatEnded(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "is closed") || strings.Contains(msg, "GROUPCALL_INVALID")
+}
+
+// abandonCall stops a chat whose dropped call cannot be rejoined and tells it why.
+func (c *TelegramCalls) abandonCall(chatID int64, key string) {
+	logger.Warn("[abandonCall] Giving up on the call in chat %d: %s", chatID, key)
+	if err := c.Stop(chatID); err != nil {
+		logger.Warn("[abandonCall] Failed to stop chat %d: %v", chatID, err)
+	}
+
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	_, _ = c.bot.SendMessage(chatID, lang.GetString(db.Instance.GetLang(ctx, chatID), key))
+}
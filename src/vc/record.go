@@ -0,0 +1,359 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package vc
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc/ntgcalls"
+	"ashokshau/tgmusic/src/vc/ubot"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+const (
+	// recordSampleRate and recordChannels are the PCM format ntgcalls delivers the call's playback in.
+	recordSampleRate = 48000
+	recordChannels   = 2
+	// recordBitrate is the Opus bitrate of recordings in kbit/s.
+	recordBitrate = 64
+	// recordFrameBuffer is how many frames may wait for the encoder before new ones are dropped.
+	recordFrameBuffer = 500
+)
+
+// recorder captures the playback stream of a chat's group call, which is everything the assistant hears,
+// and encodes it to Opus/OGG parts.
+type recorder struct {
+	chatID  int64
+	call    *ubot.Context
+	started time.Time
+	pattern string // pattern is the glob matching the recording's parts.
+
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	frames chan []byte
+	quit   chan struct{}
+	done   chan struct{}
+	limit  *time.Timer
+}
+
+// recorders holds the running recording of every chat that has one.
+var recorders = struct {
+	sync.Mutex
+	byChat map[int64]*recorder
+}{byChat: make(map[int64]*recorder)}
+
+// getRecorder returns the chat's running recording, or nil if it has none.
+func getRecorder(chatID int64) *recorder {
+	recorders.Lock()
+	defer recorders.Unlock()
+	return recorders.byChat[chatID]
+}
+
+// recordPartSeconds returns how many seconds of audio fit into one part of config.Conf.RecordPartSize.
+// Opus is encoded at a variable bitrate, so a tenth of the part is kept free.
+func recordPartSeconds() int64 {
+	bytesPerSecond := int64(recordBitrate * 1000 / 8)
+	return max(config.Conf.RecordPartSize*1024*1024*9/10/bytesPerSecond, 60)
+}
+
+// StartRecording starts capturing the chat's voice chat. The assistant joins the call if it is not in it yet.
+// The recording stops by itself after config.Conf.RecordMaxDuration minutes.
+func (c *TelegramCalls) StartRecording(chatID int64) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	recorders.Lock()
+	_, running := recorders.byChat[chatID]
+	recorders.Unlock()
+	if running {
+		return errors.New(lang.GetString(langCode, "record_already_running"))
+	}
+
+	call, err := c.GetGroupAssistant(chatID)
+	if err != nil {
+		return err
+	}
+	if err := c.joinAssistant(chatID, call.App.Me().ID); err != nil {
+		return err
+	}
+
+	r, err := startRecorder(chatID, call)
+	if err != nil {
+		return err
+	}
+	maxDuration := time.Duration(config.Conf.RecordMaxDuration) * time.Minute
+	r.limit = time.AfterFunc(maxDuration, func() {
+		if takeRecorder(chatID, r) {
+			c.endRecording(r, "record_limit_reached")
+		}
+	})
+
+	recorders.Lock()
+	if _, ok := recorders.byChat[chatID]; ok {
+		recorders.Unlock()
+		r.finish()
+		r.removeParts()
+		return errors.New(lang.GetString(langCode, "record_already_running"))
+	}
+	recorders.byChat[chatID] = r
+	recorders.Unlock()
+
+	speaker := ntgcalls.MediaDescription{
+		Speaker: &ntgcalls.AudioDescription{
+			MediaSource:  ntgcalls.MediaSourceExternal,
+			SampleRate:   recordSampleRate,
+			ChannelCount: recordChannels,
+		},
+	}
+	if err := call.Record(chatID, speaker); err != nil {
+		takeRecorder(chatID, r)
+		r.finish()
+		r.removeParts()
+		return err
+	}
+
+	logger.Info("[StartRecording] Recording chat %d to %s", chatID, r.pattern)
+	return nil
+}
+
+// startRecorder starts the encoder of a new recording of the chat.
+func startRecorder(chatID int64, call *ubot.Context) (*recorder, error) {
+	dir := filepath.Join(config.Conf.DownloadsDir, "recordings")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	base := filepath.Join(dir, fmt.Sprintf("%d_%d", chatID, time.Now().Unix()))
+
+	// Frames only arrive while someone speaks, so they are stamped with the wall clock and the gaps filled with silence.
+	// The -t cap ends the encoder even if the timer that stops the recording fires late.
+	args := []string{
+		"-v", "quiet",
+		"-use_wallclock_as_timestamps", "1",
+		"-f", "s16le", "-ar", fmt.Sprint(recordSampleRate), "-ac", fmt.Sprint(recordChannels), "-i", "pipe:0",
+		"-af", "aresample=async=1",
+		"-c:a", "libopus", "-b:a", fmt.Sprintf("%dk", recordBitrate),
+		"-t", fmt.Sprint(config.Conf.RecordMaxDuration * 60),
+		"-f", "segment", "-segment_time", fmt.Sprint(recordPartSeconds()), "-segment_format", "ogg", "-reset_timestamps", "1",
+		base + "_%03d.ogg",
+	}
+	cmd := exec.Command("ffmpeg", args...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	r := &recorder{
+		chatID:  chatID,
+		call:    call,
+		started: time.Now(),
+		pattern: base + "_*.ogg",
+		cmd:     cmd,
+		stdin:   stdin,
+		frames:  make(chan []byte, recordFrameBuffer),
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go r.encode()
+	return r, nil
+}
+
+// encode writes the recorded frames to the encoder until the recording ends, then waits for the encoder to finish.
+func (r *recorder) encode() {
+	defer close(r.done)
+	defer func() {
+		_ = r.stdin.Close()
+		_ = r.cmd.Wait()
+	}()
+
+	for {
+		select {
+		case frame := <-r.frames:
+			if _, err := r.stdin.Write(frame); err != nil {
+				logger.Warn("[Record] The encoder of chat %d stopped: %v", r.chatID, err)
+				<-r.quit
+				return
+			}
+		case <-r.quit:
+			return
+		}
+	}
+}
+
+// finish ends the recording's input and waits until its last part is written.
+func (r *recorder) finish() {
+	if r.limit != nil {
+		r.limit.Stop()
+	}
+	close(r.quit)
+	<-r.done
+}
+
+// parts returns the files of the recording in order.
+func (r *recorder) parts() []string {
+	parts, _ := filepath.Glob(r.pattern)
+	sort.Strings(parts)
+	return parts
+}
+
+// removeParts deletes the files of the recording.
+func (r *recorder) removeParts() {
+	for _, part := range r.parts() {
+		_ = os.Remove(part)
+	}
+}
+
+// recordFrames mixes the frames the call's participants sent in one interval and queues them for the encoder.
+func recordFrames(chatID int64, frames []ntgcalls.Frame) {
+	r := getRecorder(chatID)
+	if r == nil || len(frames) == 0 {
+		return
+	}
+
+	size := 0
+	for _, f := range frames {
+		size = max(size, len(f.Data))
+	}
+	mixed := make([]int32, size/2)
+	for _, f := range frames {
+		for i := 0; i+1 < len(f.Data); i += 2 {
+			mixed[i/2] += int32(int16(binary.LittleEndian.Uint16(f.Data[i:])))
+		}
+	}
+	pcm := make([]byte, len(mixed)*2)
+	for i, sample := range mixed {
+		sample = min(max(sample, math.MinInt16), math.MaxInt16)
+		binary.LittleEndian.PutUint16(pcm[i*2:], uint16(int16(sample)))
+	}
+
+	select {
+	case r.frames <- pcm:
+	default:
+		logger.Debug("[Record] The encoder of chat %d is behind, dropping a frame", chatID)
+	}
+}
+
+// takeRecorder removes r as the chat's recording. It returns false if the chat's recording is another one
+// or has already been taken, so only one caller ends a recording.
+func takeRecorder(chatID int64, r *recorder) bool {
+	recorders.Lock()
+	defer recorders.Unlock()
+	if recorders.byChat[chatID] != r {
+		return false
+	}
+	delete(recorders.byChat, chatID)
+	return true
+}
+
+// StopRecording stops the chat's recording and uploads it in the background.
+func (c *TelegramCalls) StopRecording(chatID int64) error {
+	r := getRecorder(chatID)
+	if r == nil || !takeRecorder(chatID, r) {
+		ctx, cancel := db.Ctx()
+		defer cancel()
+		return errors.New(lang.GetString(db.Instance.GetLang(ctx, chatID), "record_not_running"))
+	}
+
+	go c.endRecording(r, "record_stopped")
+	return nil
+}
+
+// IsRecording reports whether the chat's voice chat is being recorded.
+func IsRecording(chatID int64) bool {
+	return getRecorder(chatID) != nil
+}
+
+// detachRecorder stops the call's playback stream, and leaves the call the recording kept open
+// if nothing plays in the chat.
+func (c *TelegramCalls) detachRecorder(r *recorder) {
+	if _, ok := r.call.Calls()[r.chatID]; !ok {
+		return
+	}
+	if err := r.call.Record(r.chatID, ntgcalls.MediaDescription{}); err != nil {
+		logger.Debug("[Record] Failed to stop the playback stream of chat %d: %v", r.chatID, err)
+	}
+	if !cache.ChatCache.IsActive(r.chatID) {
+		_ = r.call.Stop(r.chatID)
+	}
+}
+
+// endRecording finishes a recording that has been taken from its chat, tells the chat why it ended
+// and uploads its parts to config.Conf.RecordDestination.
+// A part is deleted once it is uploaded; a part that fails to upload is kept on disk and the rest are still uploaded.
+func (c *TelegramCalls) endRecording(r *recorder, reasonKey string) {
+	c.detachRecorder(r)
+	r.finish()
+
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, r.chatID)
+	length := cache.SecToMin(int(time.Since(r.started).Seconds()))
+
+	parts := r.parts()
+	if len(parts) == 0 {
+		_, _ = c.bot.SendMessage(r.chatID, lang.GetString(langCode, "record_empty"))
+		return
+	}
+
+	text := lang.GetString(langCode, reasonKey)
+	if reasonKey == "record_limit_reached" {
+		text = fmt.Sprintf(text, config.Conf.RecordMaxDuration)
+	}
+	_, _ = c.bot.SendMessage(r.chatID, text+fmt.Sprintf(lang.GetString(langCode, "record_uploading"), length, len(parts)))
+
+	target := r.chatID
+	if config.Conf.RecordDestination == config.RecordToLogger {
+		target = config.Conf.LoggerId
+	}
+	failed := 0
+	for i, part := range parts {
+		caption := fmt.Sprintf(lang.GetString(langCode, "record_caption"), r.chatID, r.started.Format(time.DateTime), i+1, len(parts))
+		if _, err := c.bot.SendMedia(target, part, &tg.MediaOptions{Caption: caption, ForceDocument: true}); err != nil {
+			logger.Warn("[Record] Failed to upload part %d of the recording of chat %d, keeping %s: %v", i+1, r.chatID, part, err)
+			_, _ = c.bot.SendMessage(r.chatID, fmt.Sprintf(lang.GetString(langCode, "record_upload_failed"), i+1, err.Error()))
+			failed++
+			continue
+		}
+		_ = os.Remove(part)
+	}
+	if failed > 0 {
+		_, _ = c.bot.SendMessage(r.chatID, fmt.Sprintf(lang.GetString(langCode, "record_parts_kept"), failed))
+		return
+	}
+	if target != r.chatID {
+		_, _ = c.bot.SendMessage(r.chatID, lang.GetString(langCode, "record_sent_to_logger"))
+	}
+}
+
+// RecordLine returns the /queue line showing the chat's running recording, or an empty string if it has none.
+func RecordLine(langCode string, chatID int64) string {
+	r := getRecorder(chatID)
+	if r == nil {
+		return ""
+	}
+	return fmt.Sprintf(lang.GetString(langCode, "record_queue_line"), cache.SecToMin(int(time.Since(r.started).Seconds())))
+}
@@ -0,0 +1,80 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package vc
+
+import (
+	"errors"
+
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+)
+
+// SetRepeat repeats the section of the current track between start and end, in seconds, until it is cleared.
+// Playback jumps to start at once.
+func (c *TelegramCalls) SetRepeat(chatID int64, start, end int) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	playingSong := cache.ChatCache.GetPlayingTrack(chatID)
+	if playingSong == nil {
+		return errors.New(lang.GetString(langCode, "no_song_playing"))
+	}
+	if start < 0 || end <= start || (playingSong.Duration > 0 && end > playingSong.Duration) {
+		return errors.New(lang.GetString(langCode, "abloop_invalid"))
+	}
+
+	cache.ChatCache.UpdatePlayback(chatID, func(state *cache.PlaybackState) {
+		state.RepeatStart, state.RepeatEnd = start, end
+	})
+	return c.PlayMedia(chatID, playingSong.FilePath, playingSong.IsVideo, start)
+}
+
+// ClearRepeat stops repeating a section and continues the current track from its current position.
+func (c *TelegramCalls) ClearRepeat(chatID int64) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	playingSong := cache.ChatCache.GetPlayingTrack(chatID)
+	if playingSong == nil {
+		return errors.New(lang.GetString(langCode, "no_song_playing"))
+	}
+
+	return c.restartWith(chatID, playingSong, clearRepeat)
+}
+
+// repeatSection restarts the repeated section of a chat whose stream has ended.
+// It returns false if the chat does not repeat a section, so the caller moves on to the next track.
+func (c *TelegramCalls) repeatSection(chatID int64) bool {
+	state := cache.ChatCache.GetPlayback(chatID)
+	playingSong := cache.ChatCache.GetPlayingTrack(chatID)
+	if state.RepeatEnd == 0 || playingSong == nil {
+		return false
+	}
+
+	if err := c.playMedia(chatID, playingSong.FilePath, playingSong.IsVideo, state.RepeatStart, streamStart{}); err != nil {
+		c.bot.Log.Warn("[repeatSection] Failed to repeat the section in chat %d: %v", chatID, err)
+		return false
+	}
+	return true
+}
+
+// clearRepeat removes the repeated section from a playback state.
+func clearRepeat(state *cache.PlaybackState) {
+	state.RepeatStart, state.RepeatEnd = 0, 0
+}
+
+// resetTrack drops what only applied to the previous track from a playback state:
+// its repeated section, speed and effect.
+func resetTrack(state *cache.PlaybackState) {
+	clearRepeat(state)
+	state.Speed, state.Effect = 1, ""
+}
@@ -0,0 +1,106 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package vc
+
+import (
+	"sync"
+
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/vc/ntgcalls"
+)
+
+// liveMedia holds the media description each chat's ntgcalls stream was last given, so a switch between
+// camera and screen share can send the running audio description back untouched.
+var liveMedia = struct {
+	sync.Mutex
+	byChat map[int64]ntgcalls.MediaDescription
+}{byChat: make(map[int64]ntgcalls.MediaDescription)}
+
+// setLiveMedia records the media description a chat's stream was started with.
+func setLiveMedia(chatID int64, desc ntgcalls.MediaDescription) {
+	liveMedia.Lock()
+	liveMedia.byChat[chatID] = desc
+	liveMedia.Unlock()
+}
+
+// getLiveMedia returns the media description a chat's ntgcalls stream is playing.
+func getLiveMedia(chatID int64) (ntgcalls.MediaDescription, bool) {
+	liveMedia.Lock()
+	defer liveMedia.Unlock()
+	desc, ok := liveMedia.byChat[chatID]
+	return desc, ok
+}
+
+// forgetLiveMedia drops the media description of a chat that no longer streams through ntgcalls.
+func forgetLiveMedia(chatID int64) {
+	liveMedia.Lock()
+	delete(liveMedia.byChat, chatID)
+	liveMedia.Unlock()
+}
+
+// useScreen reports whether a chat sends song's video as a screen share.
+func useScreen(chatID int64, song *cache.CachedTrack) bool {
+	if song != nil && song.Screen {
+		return true
+	}
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	return db.Instance.GetScreenShare(ctx, chatID)
+}
+
+// SetScreenShare stores whether the chat sends video tracks as a screen share instead of a camera feed,
+// and moves the current video track to that slot at its current position.
+// Only the video is restarted: the audio description is sent again as it is, which ntgcalls keeps playing.
+func (c *TelegramCalls) SetScreenShare(chatID int64, screen bool) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	if err := db.Instance.SetScreenShare(ctx, chatID, screen); err != nil {
+		return err
+	}
+
+	defer lockPlayer(chatID)()
+	song := cache.ChatCache.GetPlayingTrack(chatID)
+	if song == nil {
+		return nil
+	}
+	// A track queued with /vplay --screen follows the chat's choice from now on.
+	song.Screen = screen
+	cache.ChatCache.UpdatePlayback(chatID, func(state *cache.PlaybackState) {
+		state.Screen = screen
+	})
+
+	live, ok := getLiveMedia(chatID)
+	if !song.IsVideo || !ok || (live.Screen != nil) == screen {
+		return nil
+	}
+
+	call, err := c.GetGroupAssistant(chatID)
+	if err != nil {
+		return err
+	}
+	position, err := c.PlayedTime(chatID)
+	if err != nil {
+		return err
+	}
+
+	state := cache.ChatCache.GetPlayback(chatID)
+	state.Offset, state.Lead = int(position), 0
+	video := getMediaDescription(song.FilePath, true, state, GetQualityProfile(chatID))
+	desc := ntgcalls.MediaDescription{
+		Microphone: live.Microphone,
+		Camera:     video.Camera,
+		Screen:     video.Screen,
+	}
+	if err := call.Play(chatID, desc); err != nil {
+		return err
+	}
+	setLiveMedia(chatID, desc)
+	return nil
+}
@@ -0,0 +1,204 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package vc
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+)
+
+const (
+	// sleepFadeOut is how long the music fades out before a sleep timer stops the chat.
+	sleepFadeOut = 10 * time.Second
+	// MinSleep and MaxSleep bound the duration of a sleep timer.
+	MinSleep = time.Minute
+	MaxSleep = 12 * time.Hour
+)
+
+// sleepTimer ends a chat's session, either at a deadline or after the current track.
+// It lives outside the queue, so it survives track changes until it fires, is cancelled or the chat stops.
+type sleepTimer struct {
+	deadline   time.Time // deadline is when the chat stops, zero when it stops after the current track.
+	afterTrack bool
+	fade       *time.Timer
+	stop       *time.Timer
+}
+
+// sleepTimers holds the sleep timer of every chat that has one.
+var sleepTimers = struct {
+	sync.Mutex
+	byChat map[int64]*sleepTimer
+}{byChat: make(map[int64]*sleepTimer)}
+
+// SetSleepTimer fades out and stops the chat after d.
+func (c *TelegramCalls) SetSleepTimer(chatID int64, d time.Duration) error {
+	if err := c.requirePlaying(chatID); err != nil {
+		return err
+	}
+
+	t := &sleepTimer{deadline: time.Now().Add(d)}
+	t.fade = time.AfterFunc(max(d-sleepFadeOut, 0), func() { c.sleepFade(chatID, t) })
+	t.stop = time.AfterFunc(d, func() { c.sleepStop(chatID, t) })
+	c.replaceSleep(chatID, t)
+	return nil
+}
+
+// SetStopAfterCurrent stops the chat once the current track ends instead of playing the next one.
+func (c *TelegramCalls) SetStopAfterCurrent(chatID int64) error {
+	if err := c.requirePlaying(chatID); err != nil {
+		return err
+	}
+
+	c.replaceSleep(chatID, &sleepTimer{afterTrack: true})
+	return nil
+}
+
+// CancelSleep removes the chat's sleep timer and undoes a fade-out that has already started.
+// It returns false if the chat had no sleep timer.
+func (c *TelegramCalls) CancelSleep(chatID int64) bool {
+	if !clearSleep(chatID) {
+		return false
+	}
+
+	if !cache.ChatCache.GetPlayback(chatID).FadeUntil.IsZero() {
+		if song := cache.ChatCache.GetPlayingTrack(chatID); song != nil {
+			if err := c.restartWith(chatID, song, func(state *cache.PlaybackState) { state.FadeUntil = time.Time{} }); err != nil {
+				logger.Warn("[CancelSleep] Failed to undo the fade-out in chat %d: %v", chatID, err)
+			}
+		}
+	}
+	return true
+}
+
+// SleepLine returns the /queue line describing the chat's sleep timer, or an empty string if it has none.
+func SleepLine(langCode string, chatID int64) string {
+	sleepTimers.Lock()
+	t := sleepTimers.byChat[chatID]
+	sleepTimers.Unlock()
+
+	switch {
+	case t == nil:
+		return ""
+	case t.afterTrack:
+		return lang.GetString(langCode, "sleep_queue_after_track")
+	default:
+		left := int(time.Until(t.deadline).Round(time.Second).Seconds())
+		return fmt.Sprintf(lang.GetString(langCode, "sleep_queue_countdown"), cache.SecToMin(max(left, 0)))
+	}
+}
+
+// stopAfterTrack reports whether the chat asked to stop once its current track ends, and clears the request.
+func stopAfterTrack(chatID int64) bool {
+	sleepTimers.Lock()
+	defer sleepTimers.Unlock()
+
+	t := sleepTimers.byChat[chatID]
+	if t == nil || !t.afterTrack {
+		return false
+	}
+	delete(sleepTimers.byChat, chatID)
+	return true
+}
+
+// stopsAfterTrack reports whether the chat will stop once its current track ends.
+func stopsAfterTrack(chatID int64) bool {
+	sleepTimers.Lock()
+	defer sleepTimers.Unlock()
+
+	t := sleepTimers.byChat[chatID]
+	return t != nil && t.afterTrack
+}
+
+// requirePlaying returns an error if the chat is not playing anything.
+func (c *TelegramCalls) requirePlaying(chatID int64) error {
+	if cache.ChatCache.GetPlayingTrack(chatID) != nil {
+		return nil
+	}
+
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	return errors.New(lang.GetString(db.Instance.GetLang(ctx, chatID), "no_song_playing"))
+}
+
+// replaceSleep sets the chat's sleep timer, cancelling the one it replaces.
+func (c *TelegramCalls) replaceSleep(chatID int64, t *sleepTimer) {
+	c.CancelSleep(chatID)
+
+	sleepTimers.Lock()
+	sleepTimers.byChat[chatID] = t
+	sleepTimers.Unlock()
+}
+
+// clearSleep stops and removes the chat's sleep timer. It returns false if the chat had none.
+func clearSleep(chatID int64) bool {
+	sleepTimers.Lock()
+	t := sleepTimers.byChat[chatID]
+	delete(sleepTimers.byChat, chatID)
+	sleepTimers.Unlock()
+
+	if t == nil {
+		return false
+	}
+	if t.fade != nil {
+		t.fade.Stop()
+	}
+	if t.stop != nil {
+		t.stop.Stop()
+	}
+	return true
+}
+
+// isCurrentSleep reports whether t is still the chat's sleep timer, so a replaced timer does nothing when it fires.
+func isCurrentSleep(chatID int64, t *sleepTimer) bool {
+	sleepTimers.Lock()
+	defer sleepTimers.Unlock()
+	return sleepTimers.byChat[chatID] == t
+}
+
+// sleepFade restarts the current track at its position with a fade-out that ends when the timer stops the chat.
+func (c *TelegramCalls) sleepFade(chatID int64, t *sleepTimer) {
+	song := cache.ChatCache.GetPlayingTrack(chatID)
+	if !isCurrentSleep(chatID, t) || song == nil {
+		return
+	}
+
+	err := c.restartWith(chatID, song, func(state *cache.PlaybackState) {
+		state.FadeUntil = t.deadline
+	})
+	if err != nil {
+		logger.Warn("[sleepFade] Failed to fade out chat %d: %v", chatID, err)
+	}
+}
+
+// sleepStop stops the chat when its sleep timer runs out.
+func (c *TelegramCalls) sleepStop(chatID int64, t *sleepTimer) {
+	if !isCurrentSleep(chatID, t) {
+		return
+	}
+	clearSleep(chatID)
+	defer lockPlayer(chatID)()
+	c.endSession(chatID)
+}
+
+// endSession stops the chat for a sleep timer and tells it why. The caller runs a command of the chat's player.
+func (c *TelegramCalls) endSession(chatID int64) {
+	if err := c.stop(chatID); err != nil {
+		logger.Warn("[endSession] Failed to stop chat %d: %v", chatID, err)
+	}
+
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	_, _ = c.bot.SendMessage(chatID, lang.GetString(db.Instance.GetLang(ctx, chatID), "sleep_stopped"))
+}
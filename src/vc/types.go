@@ -30,6 +30,7 @@ type TelegramCalls struct {
 	bot              *tg.Client
 	statusCache      *cache.Cache[string]
 	inviteCache      *cache.Cache[string]
+	sessions         map[string]clientSession
 }
 
 var (
@@ -43,6 +44,7 @@ func getCalls() *TelegramCalls {
 		instance = &TelegramCalls{
 			uBContext:     make(map[string]*ubot.Context),
 			clients:       make(map[string]*tg.Client),
+			sessions:      make(map[string]clientSession),
 			clientCounter: 1,
 			statusCache:   cache.NewCache[string](2 * time.Hour),
 			inviteCache:   cache.NewCache[string](2 * time.Hour),
@@ -30,8 +30,11 @@ type Context struct {
 	self                  *tg.UserObj
 	callbacksMutex        sync.RWMutex
 	incomingCallCallbacks []func(client *Context, chatId int64)
+	discardCallbacks      []func(client *Context, chatId int64)
 	streamEndCallbacks    []ntgcalls.StreamEndCallback
 	frameCallbacks        []ntgcalls.FrameCallback
+	participantsCallbacks []func(chatId int64)
+	connectionCallbacks   []ntgcalls.ConnectionChangeCallback
 }
 
 func NewInstance(app *tg.Client) (*Context, error) {
@@ -66,6 +69,12 @@ func (ctx *Context) OnIncomingCall(callback func(client *Context, chatId int64))
 	ctx.incomingCallCallbacks = append(ctx.incomingCallCallbacks, callback)
 }
 
+func (ctx *Context) OnCallDiscarded(callback func(client *Context, chatId int64)) {
+	ctx.callbacksMutex.Lock()
+	defer ctx.callbacksMutex.Unlock()
+	ctx.discardCallbacks = append(ctx.discardCallbacks, callback)
+}
+
 func (ctx *Context) OnStreamEnd(callback ntgcalls.StreamEndCallback) {
 	ctx.callbacksMutex.Lock()
 	defer ctx.callbacksMutex.Unlock()
@@ -78,6 +87,18 @@ func (ctx *Context) OnFrame(callback ntgcalls.FrameCallback) {
 	ctx.frameCallbacks = append(ctx.frameCallbacks, callback)
 }
 
+func (ctx *Context) OnParticipantsChange(callback func(chatId int64)) {
+	ctx.callbacksMutex.Lock()
+	defer ctx.callbacksMutex.Unlock()
+	ctx.participantsCallbacks = append(ctx.participantsCallbacks, callback)
+}
+
+func (ctx *Context) OnConnectionChange(callback ntgcalls.ConnectionChangeCallback) {
+	ctx.callbacksMutex.Lock()
+	defer ctx.callbacksMutex.Unlock()
+	ctx.connectionCallbacks = append(ctx.connectionCallbacks, callback)
+}
+
 func (ctx *Context) Close() {
 	ctx.binding.Free()
 }
@@ -100,6 +100,13 @@ func (ctx *Context) handleUpdates() {
 			delete(ctx.inputCalls, userId)
 			ctx.inputCallsMutex.Unlock()
 			_ = ctx.binding.Stop(userId)
+			if userId != 0 {
+				ctx.callbacksMutex.RLock()
+				for _, callback := range ctx.discardCallbacks {
+					go callback(ctx, userId)
+				}
+				ctx.callbacksMutex.RUnlock()
+			}
 		case *tg.PhoneCallRequested:
 			ctx.p2pMutex.RLock()
 			exists := ctx.p2pConfigs[userId] != nil
@@ -176,6 +183,11 @@ func (ctx *Context) handleUpdates() {
 
 			ctx.callParticipants[chatId].LastMtprotoUpdate = time.Now()
 			ctx.participantsMutex.Unlock()
+			ctx.callbacksMutex.RLock()
+			for _, callback := range ctx.participantsCallbacks {
+				go callback(chatId)
+			}
+			ctx.callbacksMutex.RUnlock()
 			for endpoint, sources := range addVideo {
 				_, _ = ctx.binding.AddIncomingVideo(chatId, endpoint, sources)
 			}
@@ -338,6 +350,12 @@ func (ctx *Context) handleUpdates() {
 			default:
 			}
 		}
+
+		ctx.callbacksMutex.RLock()
+		for _, callback := range ctx.connectionCallbacks {
+			go callback(chatId, state)
+		}
+		ctx.callbacksMutex.RUnlock()
 	})
 
 	ctx.binding.OnUpgrade(func(chatId int64, state ntgcalls.MediaState) {
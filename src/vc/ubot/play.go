@@ -6,7 +6,11 @@ import (
 
 func (ctx *Context) Play(chatId int64, mediaDescription ntgcalls.MediaDescription) error {
 	if ctx.binding.Calls()[chatId] != nil {
-		return ctx.binding.SetStreamSources(chatId, ntgcalls.CaptureStream, mediaDescription)
+		err := ctx.binding.SetStreamSources(chatId, ntgcalls.CaptureStream, mediaDescription)
+		if err != nil || chatId > 0 {
+			return err
+		}
+		return ctx.joinPresentation(chatId, mediaDescription.Screen != nil)
 	}
 	err := ctx.connectCall(chatId, mediaDescription, "")
 	if err != nil {
@@ -0,0 +1,7 @@
+package ubot
+
+import "ashokshau/tgmusic/src/vc/ntgcalls"
+
+func (ctx *Context) SendExternalFrame(chatId int64, streamDevice ntgcalls.StreamDevice, data []byte, frameData ntgcalls.FrameData) error {
+	return ctx.binding.SendExternalFrame(chatId, streamDevice, data, frameData)
+}
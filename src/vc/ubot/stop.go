@@ -1,8 +1,18 @@
 package ubot
 
-import "fmt"
+import (
+	"fmt"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
 
 func (ctx *Context) Stop(chatId int64) error {
+	if chatId >= 0 {
+		// A private call may still be ringing, so there is no stream to stop before it is hung up.
+		_ = ctx.binding.Stop(chatId)
+		return ctx.discardCall(chatId)
+	}
+
 	ctx.presentationsMutex.Lock()
 	ctx.presentations = stdRemove(ctx.presentations, chatId)
 	ctx.presentationsMutex.Unlock()
@@ -29,3 +39,30 @@ func (ctx *Context) Stop(chatId int64) error {
 	}
 	return nil
 }
+
+// discardCall hangs up the private call with a user, or declines it while it rings.
+func (ctx *Context) discardCall(userId int64) error {
+	ctx.inputCallsMutex.Lock()
+	call := ctx.inputCalls[userId]
+	delete(ctx.inputCalls, userId)
+	ctx.inputCallsMutex.Unlock()
+
+	ctx.p2pMutex.Lock()
+	delete(ctx.p2pConfigs, userId)
+	ctx.p2pMutex.Unlock()
+
+	if call == nil {
+		return fmt.Errorf("no active call found for user %v", userId)
+	}
+	_, err := ctx.App.PhoneDiscardCall(&tg.PhoneDiscardCallParams{
+		Peer:   call,
+		Reason: &tg.PhoneCallDiscardReasonHangup{},
+	})
+
+	ctx.callbacksMutex.RLock()
+	for _, callback := range ctx.discardCallbacks {
+		go callback(ctx, userId)
+	}
+	ctx.callbacksMutex.RUnlock()
+	return err
+}
@@ -47,24 +47,24 @@ func (c *TelegramCalls) joinAssistant(chatID, ubID int64) error {
 		botStatus, err := cache.GetUserAdmin(c.bot, chatID, c.bot.Me().ID, false)
 		if err != nil {
 			if strings.Contains(err.Error(), "is not an admin in chat") {
-				return fmt.Errorf(lang.GetString(langCode, "unban_fail_no_admin"), ubID)
+				return chatBan(isBanned, fmt.Errorf(lang.GetString(langCode, "unban_fail_no_admin"), ubID))
 			}
 			logger.Warn("An error occurred while checking the bot's admin status: %v", err)
 			return fmt.Errorf(lang.GetString(langCode, "check_admin_status_fail"), err)
 		}
 
 		if botStatus.Status != tg.Admin {
-			return fmt.Errorf(lang.GetString(langCode, "unban_fail_bot_not_admin"), ubID)
+			return chatBan(isBanned, fmt.Errorf(lang.GetString(langCode, "unban_fail_bot_not_admin"), ubID))
 		}
 
 		if botStatus.Rights != nil && !botStatus.Rights.BanUsers {
-			return fmt.Errorf(lang.GetString(langCode, "unban_fail_no_perm"), ubID)
+			return chatBan(isBanned, fmt.Errorf(lang.GetString(langCode, "unban_fail_no_perm"), ubID))
 		}
 
 		_, err = c.bot.EditBanned(chatID, ubID, &tg.BannedOptions{Unban: isBanned, Unmute: isMuted})
 		if err != nil {
 			logger.Warn("Failed to unban the assistant: %v", err)
-			return fmt.Errorf(lang.GetString(langCode, "unban_fail"), ubID, err)
+			return chatBan(isBanned, fmt.Errorf(lang.GetString(langCode, "unban_fail"), ubID, err))
 		}
 
 		if isBanned {
@@ -78,6 +78,22 @@ func (c *TelegramCalls) joinAssistant(chatID, ubID int64) error {
 	}
 }
 
+// chatBanError reports that an assistant is banned from a chat and the bot cannot unban it.
+type chatBanError struct {
+	err error
+}
+
+func (e *chatBanError) Error() string { return e.err.Error() }
+func (e *chatBanError) Unwrap() error { return e.err }
+
+// chatBan marks err as a ban the bot cannot lift when the assistant is banned, so the chat can move to another assistant.
+func chatBan(isBanned bool, err error) error {
+	if !isBanned {
+		return err
+	}
+	return &chatBanError{err: err}
+}
+
 // checkUserStats checks the membership status of a user in a given chat.
 // It returns the user's status as a string and an error if one occurs.
 func (c *TelegramCalls) checkUserStats(chatId int64) (string, error) {
@@ -144,6 +160,7 @@ func (c *TelegramCalls) joinUb(chatID int64) error {
 	ub := call.App
 	_, err = ub.JoinChannel(link)
 	if err != nil {
+		c.noteAssistantError(c.assistantName(call), err)
 		if strings.Contains(err.Error(), "INVITE_REQUEST_SENT") {
 			peer, err := c.bot.ResolvePeer(chatID)
 			if err != nil {
@@ -0,0 +1,181 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/priscydhon/hectormusicbot
+ */
+
+package vc
+
+import (
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/vc/ntgcalls"
+)
+
+const (
+	// watchdogInterval is how often the progress of every active stream is checked.
+	watchdogInterval = 5 * time.Second
+	// stallTimeout is how long a playing stream may go without progress before it counts as stalled.
+	stallTimeout = 20 * time.Second
+	// maxStallRestarts is how many times a track is restarted after stalls before it is skipped.
+	maxStallRestarts = 2
+)
+
+// streamWatch tracks the progress of a chat's stream.
+type streamWatch struct {
+	filePath   string                   // filePath is the track the progress belongs to.
+	streamed   float64                  // streamed is the last seen number of seconds the stream has played.
+	progressAt time.Time                // progressAt is when streamed last grew.
+	stalls     int                      // stalls counts the stalls of the current track.
+	network    ntgcalls.ConnectionState // network is the last connection state ntgcalls reported.
+}
+
+// streamWatches holds the progress of every watched stream.
+var streamWatches = struct {
+	sync.Mutex
+	byChat map[int64]*streamWatch
+}{byChat: make(map[int64]*streamWatch)}
+
+// watchStreams checks every active stream for progress and recovers the stalled ones.
+// A stream stalls when ffmpeg hangs or its source stops sending data, which ntgcalls never reports as an end.
+func (c *TelegramCalls) watchStreams() {
+	ticker := time.NewTicker(watchdogInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for _, chatID := range cache.ChatCache.GetActiveChats() {
+			c.checkStream(chatID)
+		}
+	}
+}
+
+// checkStream records the progress of a chat's stream and recovers it once it has stalled.
+// Paused streams and streams whose connection is down are not stalled, since ntgcalls is not expected to play them.
+func (c *TelegramCalls) checkStream(chatID int64) {
+	song := cache.ChatCache.GetPlayingTrack(chatID)
+	if song == nil {
+		forgetStream(chatID)
+		return
+	}
+
+	gen := getPlayer(chatID).currentGeneration()
+	streamed, playing, ok := c.streamProgress(chatID)
+	if !ok {
+		return
+	}
+
+	streamWatches.Lock()
+	w := streamWatches.byChat[chatID]
+	if w == nil {
+		w = &streamWatch{network: ntgcalls.Connected}
+		streamWatches.byChat[chatID] = w
+	}
+	if w.filePath != song.FilePath {
+		w.filePath, w.stalls = song.FilePath, 0
+		w.streamed, w.progressAt = streamed, time.Now()
+	}
+	// A restart starts the stream's clock again, so any change counts as progress.
+	if streamed != w.streamed || !playing || w.network != ntgcalls.Connected {
+		w.streamed, w.progressAt = streamed, time.Now()
+	}
+	stalled := time.Since(w.progressAt) >= stallTimeout
+	if stalled {
+		w.stalls++
+		w.progressAt = time.Now()
+	}
+	stalls := w.stalls
+	streamWatches.Unlock()
+
+	if stalled {
+		c.recoverStall(chatID, song, gen, streamed, stalls)
+	}
+}
+
+// streamProgress returns how many seconds a chat's stream has played and whether it should be playing.
+// It returns ok false if the progress cannot be read. A stream the chat's player is loading or has paused
+// is not expected to play.
+func (c *TelegramCalls) streamProgress(chatID int64) (streamed float64, playing, ok bool) {
+	state, _ := c.GetPlayerState(chatID)
+	if p := getExternalPlayer(chatID); p != nil {
+		return p.streamed(), state == PlayerPlaying && !p.paused.Load(), true
+	}
+
+	call, err := c.GetGroupAssistant(chatID)
+	if err != nil {
+		return 0, false, false
+	}
+	info, exists := call.Calls()[chatID]
+	if !exists {
+		return 0, false, false
+	}
+	seconds, err := call.Time(chatID, 0)
+	if err != nil {
+		return 0, false, false
+	}
+	return float64(seconds), state == PlayerPlaying && info.Playback == ntgcalls.ActiveStream, true
+}
+
+// recoverStall restarts a stalled track at the position it reached, or skips it once it has stalled too often.
+// gen is the generation of the stream that stalled; nothing is done if the chat has moved on since it was checked.
+func (c *TelegramCalls) recoverStall(chatID int64, song *cache.CachedTrack, gen uint64, streamed float64, stalls int) {
+	p := getPlayer(chatID)
+	p.cmd.Lock()
+	defer p.cmd.Unlock()
+	if p.currentGeneration() != gen || cache.ChatCache.GetPlayingTrack(chatID) != song {
+		return
+	}
+
+	position := trackPosition(cache.ChatCache.GetPlayback(chatID), streamed)
+	if stalls > maxStallRestarts {
+		logger.Warn("[recoverStall] The stream in chat %d stalled %d times; skipping it", chatID, stalls)
+		go sendStreamRecovery(c.bot, chatID, song, "skipped", position, stalls)
+		if err := c.playNext(chatID); err != nil {
+			logger.Warn("[recoverStall] Failed to skip the stalled track in chat %d: %v", chatID, err)
+		}
+		return
+	}
+
+	logger.Warn("[recoverStall] The stream in chat %d stalled at %ds; restarting it", chatID, position)
+	go sendStreamRecovery(c.bot, chatID, song, "restarted", position, stalls)
+	if err := c.playMedia(chatID, song.FilePath, song.IsVideo, position, streamStart{}); err != nil {
+		logger.Warn("[recoverStall] Failed to restart the stalled stream in chat %d: %v", chatID, err)
+	}
+}
+
+// noteConnection records the connection state ntgcalls reported for a chat's call.
+func noteConnection(chatID int64, state ntgcalls.ConnectionState) {
+	streamWatches.Lock()
+	defer streamWatches.Unlock()
+
+	w := streamWatches.byChat[chatID]
+	if w == nil {
+		w = &streamWatch{}
+		streamWatches.byChat[chatID] = w
+	}
+	w.network = state
+	w.progressAt = time.Now()
+}
+
+// lastProgress returns the connection state last reported for a chat's call and how many seconds its stream
+// had played when the watchdog last saw it.
+func lastProgress(chatID int64) (ntgcalls.ConnectionState, float64) {
+	streamWatches.Lock()
+	defer streamWatches.Unlock()
+
+	w := streamWatches.byChat[chatID]
+	if w == nil {
+		return ntgcalls.Connected, 0
+	}
+	return w.network, w.streamed
+}
+
+// forgetStream stops watching a chat's stream.
+func forgetStream(chatID int64) {
+	streamWatches.Lock()
+	defer streamWatches.Unlock()
+	delete(streamWatches.byChat, chatID)
+}